@@ -1,6 +1,8 @@
 package blaze
 
 import (
+	"io"
+
 	"github.com/deveox/blaze/ctx"
 	"github.com/deveox/blaze/decoder"
 	"github.com/deveox/blaze/encoder"
@@ -33,6 +35,55 @@ func UnmarshalScopedWithChangesCtx(data []byte, v any, scope scopes.Decoding, ct
 	return AdminDecoder.UnmarshalScopedWithChangesCtx(data, v, scope, ctx)
 }
 
+// UnmarshalScopedWithChangeValues decodes the data with the given scope and returns the changed fields with their old and new values, see [decoder.Change].
+func UnmarshalScopedWithChangeValues(data []byte, v any, scope scopes.Decoding) ([]decoder.Change, error) {
+	return AdminDecoder.UnmarshalScopedWithChangeValues(data, v, scope)
+}
+
+func UnmarshalScopedWithChangeValuesCtx(data []byte, v any, scope scopes.Decoding, ctx *ctx.Ctx) ([]decoder.Change, error) {
+	return AdminDecoder.UnmarshalScopedWithChangeValuesCtx(data, v, scope, ctx)
+}
+
+// ApplyMergePatch applies the JSON Merge Patch (RFC 7386) to the target with the given scope, see [decoder.Config.ApplyMergePatch].
+func ApplyMergePatch(target any, patch []byte, scope scopes.Decoding) error {
+	return AdminDecoder.ApplyMergePatch(target, patch, scope)
+}
+
+// ApplyJSONPatch applies the JSON Patch (RFC 6902) to the target with the given scope and returns the changes, see [decoder.Config.ApplyJSONPatch].
+func ApplyJSONPatch(target any, ops []byte, scope scopes.Decoding) ([]string, error) {
+	return AdminDecoder.ApplyJSONPatch(target, ops, scope)
+}
+
+// Get returns the raw bytes of the value at the given dot-separated path, e.g. "items.3.id".
+// It returns [decoder.ErrNotFound] if the path doesn't exist.
+func Get(data []byte, path string) ([]byte, error) {
+	return AdminDecoder.Get(data, path)
+}
+
+func GetString(data []byte, path string) (string, error) {
+	return AdminDecoder.GetString(data, path)
+}
+
+func GetInt64(data []byte, path string) (int64, error) {
+	return AdminDecoder.GetInt64(data, path)
+}
+
+func GetFloat64(data []byte, path string) (float64, error) {
+	return AdminDecoder.GetFloat64(data, path)
+}
+
+func GetBool(data []byte, path string) (bool, error) {
+	return AdminDecoder.GetBool(data, path)
+}
+
+func GetValue(data []byte, path string, v any) error {
+	return AdminDecoder.GetValue(data, path, v)
+}
+
+func NewStreamDecoder(r io.Reader) *decoder.StreamDecoder {
+	return AdminDecoder.NewStreamDecoder(r)
+}
+
 func RegisterDecoder[T any](fn decoder.DecoderFn) {
 	decoder.RegisterDecoder[T](fn)
 }
@@ -55,6 +106,28 @@ func MarshalPartialCtx(v any, fields []string, short bool, ctx *ctx.Ctx) ([]byte
 	return AdminEncoder.MarshalPartialCtx(v, fields, short, ctx)
 }
 
+// Diff compares two values of the same type as they are encoded, see [encoder.Config.Diff].
+func Diff(old, new any) (*encoder.Diff, error) {
+	return AdminEncoder.Diff(old, new)
+}
+
+// MarshalSelection encodes only the selected fields of the value, see [encoder.Config.ParseSelection].
+func MarshalSelection(v any, s *encoder.Selection) ([]byte, error) {
+	return AdminEncoder.MarshalSelection(v, s)
+}
+
+func MarshalTo(w io.Writer, v any) error {
+	return AdminEncoder.MarshalTo(w, v)
+}
+
+func MarshalPartialTo(w io.Writer, v any, fields []string, short bool) error {
+	return AdminEncoder.MarshalPartialTo(w, v, fields, short)
+}
+
+func NewStreamEncoder(w io.Writer) *encoder.StreamEncoder {
+	return AdminEncoder.NewStreamEncoder(w)
+}
+
 func RegisterEncoder[T any](fn encoder.EncoderFn) {
 	encoder.RegisterEncoder[T](fn)
 }
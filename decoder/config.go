@@ -4,14 +4,26 @@ import (
 	"sync"
 
 	"github.com/deveox/blaze/ctx"
+	"github.com/deveox/blaze/encoder"
 	"github.com/deveox/blaze/scopes"
+	"github.com/deveox/blaze/types"
 )
 
 // Config is a configuration for the decoder.
 // It can be used to define the scope of the decoding ones and reuse it multiple times.
 type Config struct {
-	Scope       scopes.Context
-	decoderPool sync.Pool
+	Scope scopes.Context
+	// Policies are runtime field policies checked in addition to the field scopes, see [types.Policies].
+	Policies *types.Policies
+	// Strict enables reporting of unknown keys and keys which can't be decoded in the context and operation of the decoder.
+	// Decoding doesn't stop on such keys, all of them are collected and returned as [*FieldErrors] after decoding.
+	Strict bool
+	// CollectErrors enables recovering from values which can't be decoded into the type of their field, e.g. a string for an int field.
+	// Such values are skipped and reported as [*FieldErrors] after decoding, together with other field errors.
+	CollectErrors bool
+	decoderPool   sync.Pool
+	changesOnce   sync.Once
+	changes       *encoder.Config
 }
 
 // Unmarshal decodes the data into the given value.
@@ -54,12 +66,8 @@ func (c *Config) UnmarshalScopedWithChanges(data []byte, v any, operation scopes
 	t := c.NewDecoder(data)
 	defer c.decoderPool.Put(t)
 	t.operation = operation
-	t.Changes = make([]string, 0, 10)
 	t.Ctx.Clear()
-	err := t.unmarshal(v)
-	changes := t.Changes
-	t.Changes = nil
-	return changes, err
+	return t.unmarshalWithChanges(v)
 }
 
 // UnmarshalScopedWithChangesCtx sets the [*ctx.Ctx] and decodes the data into the given value with the given scope and returns the changes.
@@ -69,12 +77,7 @@ func (c *Config) UnmarshalScopedWithChangesCtx(data []byte, v any, operation sco
 	defer c.decoderPool.Put(t)
 	t.operation = operation
 	t.Ctx = ctx
-	t.Changes = make([]string, 0, 10)
-	err := t.unmarshal(v)
-	changes := t.Changes
-	t.Changes = nil
-	return changes, err
-
+	return t.unmarshalWithChanges(v)
 }
 
 // UnmarshalWithChanges decodes the data into the given value and returns the changes.
@@ -82,12 +85,8 @@ func (c *Config) UnmarshalScopedWithChangesCtx(data []byte, v any, operation sco
 func (c *Config) UnmarshalWithChanges(data []byte, v any) ([]string, error) {
 	t := c.NewDecoder(data)
 	defer c.decoderPool.Put(t)
-	t.Changes = make([]string, 0, 10)
 	t.Ctx.Clear()
-	err := t.unmarshal(v)
-	changes := t.Changes
-	t.Changes = nil
-	return changes, err
+	return t.unmarshalWithChanges(v)
 }
 
 // UnmarshalWithChangesCtx sets the [*ctx.Ctx] and decodes the data into the given value and returns the changes.
@@ -96,11 +95,85 @@ func (c *Config) UnmarshalWithChangesCtx(data []byte, v any, ctx *ctx.Ctx) ([]st
 	t := c.NewDecoder(data)
 	defer c.decoderPool.Put(t)
 	t.Ctx = ctx
-	t.Changes = make([]string, 0, 10)
-	err := t.unmarshal(v)
-	changes := t.Changes
-	t.Changes = nil
-	return changes, err
+	return t.unmarshalWithChanges(v)
+}
+
+// UnmarshalWithChangeValues decodes the data into the given value and returns the changed fields with their old and new values.
+// Unlike [Config.UnmarshalWithChanges] only fields, which values actually differ after decoding, are reported.
+func (c *Config) UnmarshalWithChangeValues(data []byte, v any) ([]Change, error) {
+	return c.UnmarshalScopedWithChangeValues(data, v, scopes.DECODE_ANY)
+}
+
+// UnmarshalWithChangeValuesCtx sets the [*ctx.Ctx] and decodes the data into the given value and returns the changed fields with their old and new values.
+func (c *Config) UnmarshalWithChangeValuesCtx(data []byte, v any, ctx *ctx.Ctx) ([]Change, error) {
+	return c.UnmarshalScopedWithChangeValuesCtx(data, v, scopes.DECODE_ANY, ctx)
+}
+
+// UnmarshalScopedWithChangeValues decodes the data into the given value with the given scope and returns the changed fields with their old and new values.
+func (c *Config) UnmarshalScopedWithChangeValues(data []byte, v any, operation scopes.Decoding) ([]Change, error) {
+	t := c.NewDecoder(data)
+	defer c.decoderPool.Put(t)
+	t.operation = operation
+	t.Ctx.Clear()
+	return t.unmarshalWithChangeValues(v)
+}
+
+// UnmarshalScopedWithChangeValuesCtx sets the [*ctx.Ctx] and decodes the data into the given value with the given scope and returns the changed fields with their old and new values.
+func (c *Config) UnmarshalScopedWithChangeValuesCtx(data []byte, v any, operation scopes.Decoding, ctx *ctx.Ctx) ([]Change, error) {
+	t := c.NewDecoder(data)
+	defer c.decoderPool.Put(t)
+	t.operation = operation
+	t.Ctx = ctx
+	return t.unmarshalWithChangeValues(v)
+}
+
+// UnmarshalWithChangeSet decodes the data into the given value and records the changes into the given [ChangeSet].
+// The change set is reset before decoding, so it can be reused to avoid allocations.
+func (c *Config) UnmarshalWithChangeSet(data []byte, v any, changes *ChangeSet) error {
+	return c.UnmarshalScopedWithChangeSet(data, v, scopes.DECODE_ANY, changes)
+}
+
+// UnmarshalScopedWithChangeSet decodes the data into the given value with the given scope and records the changes into the given [ChangeSet].
+func (c *Config) UnmarshalScopedWithChangeSet(data []byte, v any, operation scopes.Decoding, changes *ChangeSet) error {
+	t := c.NewDecoder(data)
+	defer c.decoderPool.Put(t)
+	t.operation = operation
+	t.Ctx.Clear()
+	return t.unmarshalWithChangeSet(v, changes)
+}
+
+// UnmarshalScopedWithChangeSetCtx sets the [*ctx.Ctx] and decodes the data into the given value with the given scope and records the changes into the given [ChangeSet].
+func (c *Config) UnmarshalScopedWithChangeSetCtx(data []byte, v any, operation scopes.Decoding, changes *ChangeSet, ctx *ctx.Ctx) error {
+	t := c.NewDecoder(data)
+	defer c.decoderPool.Put(t)
+	t.operation = operation
+	t.Ctx = ctx
+	return t.unmarshalWithChangeSet(v, changes)
+}
+
+// ApplyMergePatch applies the JSON Merge Patch (RFC 7386) to the target with the given scope.
+// Objects are merged recursively, including values of maps, while other values (arrays too) replace the old ones.
+// A null removes the key from a map, sets a pointer to nil and zeroes other fields. Fields which can't be decoded in the scope are left as is.
+//
+// Unlike RFC 7386, a null for a pointer to a struct zeroes only the fields, which can be decoded in the scope,
+// so the pointer stays non-nil if some of the other fields aren't zero.
+func (c *Config) ApplyMergePatch(target any, patch []byte, operation scopes.Decoding) error {
+	t := c.NewDecoder(patch)
+	defer c.decoderPool.Put(t)
+	t.operation = operation
+	t.Ctx.Clear()
+	t.mergePatch = true
+	return t.unmarshal(target)
+}
+
+// ApplyMergePatchCtx sets the [*ctx.Ctx] and applies the JSON Merge Patch to the target with the given scope, see [Config.ApplyMergePatch].
+func (c *Config) ApplyMergePatchCtx(target any, patch []byte, operation scopes.Decoding, ctx *ctx.Ctx) error {
+	t := c.NewDecoder(patch)
+	defer c.decoderPool.Put(t)
+	t.operation = operation
+	t.Ctx = ctx
+	t.mergePatch = true
+	return t.unmarshal(target)
 }
 
 // NewDecoder creates a new decoder with the given data.
@@ -1,10 +1,14 @@
 package decoder
 
 import (
+	"errors"
+	"reflect"
 	"testing"
 
+	"github.com/deveox/blaze/ctx"
 	"github.com/deveox/blaze/internal/testdata"
 	"github.com/deveox/blaze/scopes"
+	"github.com/deveox/blaze/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -266,3 +270,354 @@ func TestScope_Client_Update(t *testing.T) {
 		AdminWrite:       true,
 	}, s)
 }
+
+var DECODE_IMPORT = scopes.RegisterDecoding("import")
+
+type ImportedStruct struct {
+	ID     int    `blaze:"read.import"`
+	Name   string `blaze:"read.create.update"`
+	Status string `blaze:"read.create"`
+}
+
+func TestScope_Registered(t *testing.T) {
+	data := []byte(`{"id":1,"name":"test","status":"new"}`)
+	var s ImportedStruct
+	err := clientDecoder.UnmarshalScoped(data, &s, DECODE_IMPORT)
+	require.NoError(t, err)
+	// 'create.update' doesn't allow registered operations.
+	require.Equal(t, ImportedStruct{ID: 1}, s)
+
+	s = ImportedStruct{}
+	err = clientDecoder.UnmarshalScoped(data, &s, scopes.DECODE_CREATE)
+	require.NoError(t, err)
+	require.Equal(t, ImportedStruct{Name: "test", Status: "new"}, s)
+}
+
+type PolicyUser struct {
+	ID     int
+	Salary int
+}
+
+func TestPolicies(t *testing.T) {
+	p := &types.Policies{}
+	err := p.Set(reflect.TypeFor[PolicyUser](), "salary", func(c *ctx.Ctx, parent reflect.Value, f *types.Field) bool {
+		_, role := c.Get("role")
+		return role == "hr"
+	})
+	require.NoError(t, err)
+	d := &Config{Scope: scopes.CONTEXT_CLIENT, Policies: p}
+
+	data := []byte(`{"id":1,"salary":10}`)
+	var v PolicyUser
+	err = d.UnmarshalCtx(data, &v, &ctx.Ctx{})
+	require.NoError(t, err)
+	require.Equal(t, PolicyUser{ID: 1}, v)
+
+	c := &ctx.Ctx{}
+	c.Set("role", "hr")
+	v = PolicyUser{}
+	err = d.UnmarshalCtx(data, &v, c)
+	require.NoError(t, err)
+	require.Equal(t, PolicyUser{ID: 1, Salary: 10}, v)
+
+	v = PolicyUser{ID: 1, Salary: 10}
+	var wrapper struct{ User PolicyUser }
+	wrapper.User = v
+	err = d.UnmarshalCtx([]byte(`{"user":null}`), &wrapper, &ctx.Ctx{})
+	require.NoError(t, err)
+	require.Equal(t, PolicyUser{Salary: 10}, wrapper.User)
+}
+
+type StrictItem struct {
+	ID   int `blaze:"read"`
+	Name string
+}
+
+type StrictStruct struct {
+	Name  string
+	Role  string `blaze:"client:read"`
+	Items []StrictItem
+	Meta  map[string]StrictItem
+}
+
+var strictDecoder = &Config{
+	Scope:  scopes.CONTEXT_CLIENT,
+	Strict: true,
+}
+
+func TestStrict(t *testing.T) {
+	data := []byte(`{"name":"test","role":"admin","unknown":{"a":1},"items":[{"name":"a"},{"id":2,"name":"b","x":null}],"meta":{"k":{"id":3}}}`)
+	var s StrictStruct
+	err := strictDecoder.UnmarshalScoped(data, &s, scopes.DECODE_CREATE)
+	var fe *FieldErrors
+	require.ErrorAs(t, err, &fe)
+	for _, e := range fe.Errors {
+		if e.Reason == REASON_FORBIDDEN {
+			require.ErrorIs(t, e, ErrScope)
+			e.Err = nil
+		}
+	}
+	require.Equal(t, []*FieldError{
+		{Path: "role", Reason: REASON_FORBIDDEN, Message: "field can't be decoded in 'client' context with 'create' operation"},
+		{Path: "unknown", Reason: REASON_UNKNOWN, Message: "unknown field"},
+		{Path: "items[1].id", Reason: REASON_FORBIDDEN, Message: "field can't be decoded in 'client' context with 'create' operation"},
+		{Path: "items[1].x", Reason: REASON_UNKNOWN, Message: "unknown field"},
+		{Path: "meta.k.id", Reason: REASON_FORBIDDEN, Message: "field can't be decoded in 'client' context with 'create' operation"},
+	}, fe.Errors)
+	// Allowed fields are still decoded
+	require.Equal(t, StrictStruct{Name: "test", Items: []StrictItem{{Name: "a"}, {Name: "b"}}, Meta: map[string]StrictItem{"k": {}}}, s)
+	var single *FieldError
+	require.ErrorAs(t, err, &single)
+	require.Equal(t, "role", single.Path)
+
+	s = StrictStruct{}
+	err = strictDecoder.Unmarshal([]byte(`{"name":"test","items":[{"name":"a"}]}`), &s)
+	require.NoError(t, err)
+
+	// Syntax errors are returned as is
+	err = strictDecoder.Unmarshal([]byte(`{"role":"admin","name":}`), &s)
+	require.Error(t, err)
+	require.False(t, errors.As(err, &fe))
+
+	// Non-strict decoder skips such fields silently
+	err = clientDecoder.UnmarshalScoped(data, &s, scopes.DECODE_CREATE)
+	require.NoError(t, err)
+}
+
+type RequiredAddress struct {
+	City string `blaze:"required:create"`
+	Zip  string
+}
+
+type RequiredStruct struct {
+	Name    string `blaze:"required:create"`
+	Email   string `blaze:"client:read.required.update"`
+	Address *RequiredAddress
+	Items   []RequiredAddress
+}
+
+func TestRequired(t *testing.T) {
+	var s RequiredStruct
+	err := clientDecoder.UnmarshalScoped([]byte(`{"address":{"zip":"1"},"items":[{"city":"a"},{"city":null}]}`), &s, scopes.DECODE_CREATE)
+	var fe *FieldErrors
+	require.ErrorAs(t, err, &fe)
+	require.Equal(t, []*FieldError{
+		{Path: "address.city", Reason: REASON_REQUIRED, Message: "field is required"},
+		{Path: "items[1].city", Reason: REASON_REQUIRED, Message: "field is required and can't be null"},
+		{Path: "name", Reason: REASON_REQUIRED, Message: "field is required"},
+	}, fe.Errors)
+
+	s = RequiredStruct{}
+	err = clientDecoder.UnmarshalScoped([]byte(`{"name":"a","items":[]}`), &s, scopes.DECODE_CREATE)
+	require.NoError(t, err)
+
+	err = clientDecoder.UnmarshalScoped([]byte(`{"name":"a"}`), &s, scopes.DECODE_UPDATE)
+	require.ErrorAs(t, err, &fe)
+	require.Equal(t, []*FieldError{{Path: "email", Reason: REASON_REQUIRED, Message: "field is required"}}, fe.Errors)
+
+	// Required fields are not checked without an operation
+	err = clientDecoder.Unmarshal([]byte(`{}`), &s)
+	require.NoError(t, err)
+}
+
+type RequiredNested struct {
+	Address  RequiredAddress `blaze:"required:create"`
+	Optional *RequiredAddress
+}
+
+func TestRequired_Null(t *testing.T) {
+	// null for a required struct is reported for the struct itself, the required fields of the struct aren't checked.
+	var s RequiredNested
+	err := clientDecoder.UnmarshalScoped([]byte(`{"address":null,"optional":null}`), &s, scopes.DECODE_CREATE)
+	var fe *FieldErrors
+	require.ErrorAs(t, err, &fe)
+	require.Equal(t, []*FieldError{
+		{Path: "address", Reason: REASON_REQUIRED, Message: "field is required and can't be null"},
+	}, fe.Errors)
+
+	err = clientDecoder.UnmarshalScoped([]byte(`{"address":{},"optional":{}}`), &s, scopes.DECODE_CREATE)
+	require.ErrorAs(t, err, &fe)
+	require.Equal(t, []*FieldError{
+		{Path: "address.city", Reason: REASON_REQUIRED, Message: "field is required"},
+		{Path: "optional.city", Reason: REASON_REQUIRED, Message: "field is required"},
+	}, fe.Errors)
+}
+
+type ValidatedItem struct {
+	Qty int `blaze-validate:"min=1,max=100"`
+}
+
+type ValidatedStruct struct {
+	Name   string  `blaze-validate:"min=2,pattern=^[a-z]+$"`
+	Email  *string `blaze-validate:"email"`
+	Status string  `blaze-validate:"enum=new|done" blaze:"client:read"`
+	Items  []ValidatedItem
+}
+
+func TestValidate(t *testing.T) {
+	var s ValidatedStruct
+	err := clientDecoder.Unmarshal([]byte(`{"name":"A","email":"x","status":"bad","items":[{"qty":1},{"qty":0}]}`), &s)
+	var fe *FieldErrors
+	require.ErrorAs(t, err, &fe)
+	// status can't be decoded in client scope, so it's not validated
+	require.Equal(t, []*FieldError{
+		{Path: "name", Reason: REASON_INVALID, Message: "length must be at least 2"},
+		{Path: "name", Reason: REASON_INVALID, Message: "must match pattern ^[a-z]+$"},
+		{Path: "email", Reason: REASON_INVALID, Message: "must be a valid email address"},
+		{Path: "items[1].qty", Reason: REASON_INVALID, Message: "value must be at least 1"},
+	}, fe.Errors)
+
+	s = ValidatedStruct{}
+	err = clientDecoder.Unmarshal([]byte(`{"name":"ab","email":null,"items":[{"qty":100}]}`), &s)
+	require.NoError(t, err)
+
+	err = adminDecoder.Unmarshal([]byte(`{"status":"bad"}`), &s)
+	require.ErrorAs(t, err, &fe)
+	require.Equal(t, []*FieldError{{Path: "status", Reason: REASON_INVALID, Message: "must be one of new, done"}}, fe.Errors)
+}
+
+type InvalidRulesStruct struct {
+	Name string `validate:"required,gte=0"`
+	Flag bool   `blaze-validate:"min=1"`
+}
+
+func TestValidate_InvalidRules(t *testing.T) {
+	var s InvalidRulesStruct
+	// `validate` tag of other libraries is ignored
+	require.NoError(t, clientDecoder.Unmarshal([]byte(`{"name":"a"}`), &s))
+	require.Equal(t, "a", s.Name)
+
+	err := clientDecoder.Unmarshal([]byte(`{"flag":true}`), &s)
+	require.ErrorContains(t, err, "[Blaze parseRules()] invalid rule 'min=1' for type bool")
+}
+
+type CollectItem struct {
+	Qty   int
+	Price float64
+}
+
+type CollectStruct struct {
+	Name   string
+	Age    int `blaze-validate:"min=1"`
+	Tags   []string
+	Flags  map[string]bool
+	Items  []CollectItem
+	Nested struct {
+		Zip int `blaze:"string"`
+	}
+	Last string
+}
+
+var collectDecoder = &Config{
+	CollectErrors: true,
+}
+
+func TestCollectErrors(t *testing.T) {
+	data := []byte(`{"name":1,"age":"x","tags":["a",{"b":[1,2]},"c"],"flags":{"k":1},"items":[{"qty":1.5,"price":true},{"qty":2}],"nested":{"zip":"a1"},"last":"ok"}`)
+	var s CollectStruct
+	err := collectDecoder.Unmarshal(data, &s)
+	var fe *FieldErrors
+	require.ErrorAs(t, err, &fe)
+	type short struct {
+		Path, Message, Type, Raw string
+	}
+	res := []short{}
+	for _, e := range fe.Errors {
+		require.Equal(t, REASON_TYPE, e.Reason)
+		require.Error(t, e.Err)
+		res = append(res, short{e.Path, e.Message, e.Type, e.Raw})
+	}
+	require.Equal(t, []short{
+		{"name", "can't decode number into string", "string", "1"},
+		{"age", "can't decode string into int", "int", `"x"`},
+		{"tags[1]", "can't decode object into string", "string", `{"b":[1,2]}`},
+		{"flags.k", "can't decode number into bool", "bool", "1"},
+		{"items[0].qty", "can't decode number into int", "int", "1.5"},
+		{"items[0].price", "can't decode boolean into float64", "float64", "true"},
+		{"nested.zip", "can't decode string into int", "int", `"a1"`},
+	}, res)
+	require.Equal(t, []string{"a", "", "c"}, s.Tags)
+	require.Equal(t, 2, s.Items[1].Qty)
+	require.Equal(t, "ok", s.Last)
+
+	// Syntax errors stop decoding
+	err = collectDecoder.Unmarshal([]byte(`{"name":1,"age":}`), &s)
+	require.Error(t, err)
+	require.False(t, errors.As(err, &fe))
+
+	// Without the option the first error is returned
+	err = adminDecoder.Unmarshal(data, &s)
+	require.Error(t, err)
+	require.False(t, errors.As(err, &fe))
+}
+
+type MergeAddress struct {
+	City string
+	Zip  string `blaze:"client:read"`
+}
+
+type MergeItem struct {
+	Name string
+	Qty  int
+}
+
+type MergeStruct struct {
+	Title    string
+	Count    *int
+	Address  *MergeAddress
+	Tags     []string
+	Items    []MergeItem
+	Pair     [2]int
+	Settings map[string]MergeItem
+	Extra    map[string]any
+	Secret   string `blaze:"client:read"`
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	count := 1
+	v := MergeStruct{
+		Title:    "old",
+		Count:    &count,
+		Address:  &MergeAddress{City: "Paris", Zip: "75001"},
+		Tags:     []string{"a", "b"},
+		Items:    []MergeItem{{Name: "x", Qty: 1}},
+		Pair:     [2]int{1, 2},
+		Settings: map[string]MergeItem{"a": {Name: "a", Qty: 1}, "b": {Name: "b"}},
+		Extra:    map[string]any{"keep": "yes", "nested": map[string]any{"a": 1.0, "b": 2.0}, "drop": true},
+		Secret:   "secret",
+	}
+	patch := []byte(`{
+		"title": null,
+		"count": null,
+		"address": null,
+		"tags": ["c"],
+		"items": [{"qty": 2}],
+		"pair": [3],
+		"settings": {"a": {"qty": 2}, "b": null, "c": {"name": "c"}},
+		"extra": {"nested": {"a": null, "c": 3}, "drop": null},
+		"secret": "changed"
+	}`)
+	err := clientDecoder.ApplyMergePatch(&v, patch, scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+	require.Equal(t, MergeStruct{
+		Address:  &MergeAddress{Zip: "75001"},
+		Tags:     []string{"c"},
+		Items:    []MergeItem{{Qty: 2}},
+		Pair:     [2]int{3, 0},
+		Settings: map[string]MergeItem{"a": {Name: "a", Qty: 2}, "c": {Name: "c"}},
+		Extra:    map[string]any{"keep": "yes", "nested": map[string]any{"b": 2.0, "c": 3.0}},
+		Secret:   "secret",
+	}, v)
+
+	// A pointer to a struct without fields left after null is removed
+	err = adminDecoder.ApplyMergePatch(&v, []byte(`{"address":null,"settings":null}`), scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+	require.Nil(t, v.Address)
+	require.Nil(t, v.Settings)
+
+	// Without merge patch map values are replaced
+	v.Settings = map[string]MergeItem{"a": {Name: "a"}}
+	err = adminDecoder.Unmarshal([]byte(`{"settings":{"a":{"qty":1}}}`), &v)
+	require.NoError(t, err)
+	require.Equal(t, map[string]MergeItem{"a": {Qty: 1}}, v.Settings)
+}
@@ -3,6 +3,7 @@ package decoder
 import (
 	"fmt"
 	"reflect"
+	"unicode/utf8"
 	"unsafe"
 
 	"github.com/deveox/blaze/ctx"
@@ -16,15 +17,40 @@ const MAX_DEPTH = 1000
 
 type Decoder struct {
 	*ctx.Ctx
-	config        *Config
-	depth         int
-	Buf           []byte
-	ptr           unsafe.Pointer
-	pos           int64
-	start         int64
-	operation     scopes.Decoding
-	Changes       []string
+	config    *Config
+	depth     int
+	Buf       []byte
+	ptr       unsafe.Pointer
+	pos       int64
+	start     int64
+	operation scopes.Decoding
+	// changes is the change set being recorded, or nil if changes aren't tracked.
+	changes *ChangeSet
+	// changeParent is the node of the value being decoded in changes.
+	changeParent int32
+	// changeSet is reused by [Config.UnmarshalWithChanges] and [Config.UnmarshalWithChangeValues].
+	changeSet ChangeSet
+	// Changes were the paths of the changed fields, filled during decoding.
+	//
+	// Deprecated: Changes aren't filled anymore, use the paths returned by [Decoder.UnmarshalWithChanges] or [Config.UnmarshalWithChangeSet].
+	Changes []string
+	// ChangesPrefix was the path of the field being decoded, when changes were tracked.
+	//
+	// Deprecated: ChangesPrefix isn't set anymore, paths of the decoded values are reported only in [Error] and [FieldError].
 	ChangesPrefix string
+	// ChangeValues are the changed fields with their old and new values. It's nil if the values aren't tracked.
+	ChangeValues []Change
+	// wholeValue is set while decoding a value, which changes are tracked as a whole, see [Decoder.beginChange].
+	wholeValue bool
+	// mergePatch enables JSON Merge Patch semantics, see [Config.ApplyMergePatch].
+	mergePatch bool
+	// patchPath is the path of the value being resolved by [Config.ApplyJSONPatch].
+	patchPath   []byte
+	fieldErrors []*FieldError
+	// errOffset, errLine and errColumn are the position of the last error, see [Decoder.lineColumn].
+	errOffset int
+	errLine   int
+	errColumn int
 }
 
 func (d *Decoder) Unmarshal(data []byte, v any) error {
@@ -35,6 +61,7 @@ func (d *Decoder) Decoder(data []byte) *Decoder {
 	n := d.config.NewDecoder(data)
 	n.operation = d.operation
 	n.Ctx = d.Ctx
+	n.mergePatch = d.mergePatch
 	return n
 }
 
@@ -54,6 +81,14 @@ func (d *Decoder) UnmarshalWithChanges(data []byte, v any) ([]string, error) {
 	return d.config.UnmarshalScopedWithChangesCtx(data, v, d.operation, d.Ctx)
 }
 
+func (d *Decoder) UnmarshalScopedWithChangeValues(data []byte, v any, operation scopes.Decoding) ([]Change, error) {
+	return d.config.UnmarshalScopedWithChangeValuesCtx(data, v, operation, d.Ctx)
+}
+
+func (d *Decoder) UnmarshalWithChangeValues(data []byte, v any) ([]Change, error) {
+	return d.config.UnmarshalScopedWithChangeValuesCtx(data, v, d.operation, d.Ctx)
+}
+
 func (d *Decoder) Context() scopes.Context {
 	return d.config.Scope
 }
@@ -64,18 +99,30 @@ func (d *Decoder) Operation() scopes.Decoding {
 
 func (d *Decoder) decode(v reflect.Value) error {
 	return getDecoderFn(v.Type())(d, v)
-
 }
 
+// unmarshal decodes the whole input into v, it returns [*FieldErrors] if there are collected field errors.
 func (d *Decoder) unmarshal(v any) error {
+	if err := d.decodeValue(v); err != nil {
+		return err
+	}
+	return d.fieldErrorsResult()
+}
+
+func (d *Decoder) decodeValue(v any) error {
 	rv := reflect.ValueOf(v)
 	if !rv.IsValid() {
-		return d.Error("[Blaze decode()] can't decode to nil value")
+		return d.UnsupportedTypeError("[Blaze decode()] can't decode to nil value", nil)
 	}
 	if rv.Kind() != reflect.Pointer {
-		return d.ErrorF("[Blaze decode()] can't decode to non-pointer value '%s'", rv.Type())
+		return d.UnsupportedTypeError(fmt.Sprintf("[Blaze decode()] can't decode to non-pointer value '%s'", rv.Type()), rv.Type())
 	}
-	return d.decode(rv)
+	d.SkipWhitespace()
+	start, depth := d.pos, d.depth
+	if err := d.decode(rv); err != nil {
+		return d.valueError(err, rv.Elem(), start, depth, nil, nil)
+	}
+	return nil
 }
 
 func (d *Decoder) SkipWhitespace() {
@@ -98,13 +145,13 @@ func (d *Decoder) Skip() error {
 	switch c {
 	case '{':
 		if d.depth > MAX_DEPTH {
-			return d.Error("[Blaze decode()] maximum depth reached")
+			return d.DepthError("[Blaze decode()] maximum depth reached")
 		}
 		err = d.SkipObject()
 		d.depth--
 	case '[':
 		if d.depth > MAX_DEPTH {
-			return d.Error("[Blaze decode()] maximum depth reached")
+			return d.DepthError("[Blaze decode()] maximum depth reached")
 		}
 		err = d.SkipArray()
 		d.depth--
@@ -142,29 +189,45 @@ func (d *Decoder) init(data []byte) {
 	d.pos = 0
 	d.start = 0
 	d.operation = 0
-	d.ChangesPrefix = ""
-	d.Changes = d.Changes[:0]
+	d.changes = nil
+	d.changeParent = -1
+	d.ChangeValues = nil
+	d.wholeValue = false
+	d.mergePatch = false
 	d.depth = 0
+	d.patchPath = d.patchPath[:0]
+	d.errOffset, d.errLine, d.errColumn = 0, 1, 1
+	clear(d.fieldErrors)
+	d.fieldErrors = d.fieldErrors[:0]
 }
 
+// Error returns a [*SyntaxError] at the current position.
 func (d *Decoder) Error(msg string) error {
-	e := &Error{
-		Message: msg,
-		Offset:  int(d.pos),
+	return &SyntaxError{d.newError(msg)}
+}
+
+// lineColumn returns 1-based line and column (in characters) of the given offset.
+// It continues from the position of the previous error, so collecting many errors doesn't rescan the input from the beginning.
+func (d *Decoder) lineColumn(offset int) (int, int) {
+	if offset > len(d.Buf) {
+		offset = len(d.Buf)
 	}
-	areaStart := e.Offset - 20
-	if areaStart < 0 {
-		areaStart = 0
-		e.AreaPos = e.Offset
-	} else {
-		e.AreaPos = 20
+	if offset < d.errOffset || d.errLine == 0 {
+		d.errOffset, d.errLine, d.errColumn = 0, 1, 1
 	}
-	areaEnd := e.Offset + 20
-	if areaEnd > len(d.Buf) {
-		areaEnd = len(d.Buf)
+	for _, c := range d.Buf[d.errOffset:offset] {
+		switch {
+		case c == '\n':
+			d.errLine++
+			d.errColumn = 1
+		case !utf8.RuneStart(c):
+			// continuation bytes of multi-byte characters
+		default:
+			d.errColumn++
+		}
 	}
-	e.Area = d.Buf[areaStart:areaEnd]
-	return e
+	d.errOffset = offset
+	return d.errLine, d.errColumn
 }
 
 func (d *Decoder) ErrorF(format string, args ...any) error {
@@ -187,13 +250,34 @@ func BytesToString(b []byte) string {
 
 type Error struct {
 	Message string
-	Offset  int
+	// Offset is the byte offset of the error in the input.
+	Offset int
+	// Line and Column are 1-based position of the error in the input. Column is counted in characters.
+	// For [StreamDecoder] they are relative to the beginning of the decoded value.
+	Line   int
+	Column int
+	// Path is a JSON path to the value being decoded, e.g. "items[3].address.zip". It's empty for the root value.
+	Path string
+	// Type is the Go type of the value being decoded, nil if the error isn't related to a value.
+	Type reflect.Type
+	// Field is the struct field being decoded in the form "Struct.Field", e.g. "Address.Zip". It's empty outside of structs.
+	Field   string
 	Area    []byte
 	AreaPos int
 }
 
 func (e *Error) Error() string {
-	str := fmt.Sprintf("%s at position %d:\n%s\n", e.Message, e.Offset, string(e.Area))
+	str := fmt.Sprintf("%s at line %d, column %d (position %d", e.Message, e.Line, e.Column, e.Offset)
+	if e.Path != "" {
+		str += ", path " + e.Path
+	}
+	if e.Type != nil {
+		str += ", type " + e.Type.String()
+	}
+	if e.Field != "" {
+		str += ", field " + e.Field
+	}
+	str += "):\n" + string(e.Area) + "\n"
 	for i := 0; i < len(e.Area); i++ {
 		if i == e.AreaPos {
 			str += "^"
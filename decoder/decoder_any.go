@@ -46,7 +46,10 @@ func decodeAny(d *Decoder, v reflect.Value) error {
 		v.Set(vv.Elem())
 		return nil
 	case '{':
-		mp := make(map[string]any)
+		mp, ok := v.Interface().(map[string]any)
+		if !d.mergePatch || !ok || mp == nil {
+			mp = make(map[string]any)
+		}
 		vv := reflect.ValueOf(&mp)
 		err := d.decodeMap(vv.Elem(), decodeString, decodeAny)
 		if err != nil {
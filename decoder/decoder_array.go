@@ -38,6 +38,7 @@ func (d *Decoder) ScanArray() (int, error) {
 	size := 0
 	d.pos++
 	for {
+		d.SkipWhitespace()
 		c := d.char()
 		switch c {
 		case ',':
@@ -81,11 +82,11 @@ func (d *Decoder) decodeArray(v reflect.Value, elemDecoder DecoderFn) error {
 		v.SetZero()
 		return nil
 	default:
-		return d.Error("[Blaze decodeArray()] invalid char, expected '[' or 'null'")
+		return d.mismatchError("[Blaze decodeArray()] invalid char, expected '[' or 'null'")
 	}
 	d.depth++
 	if d.depth > MAX_DEPTH {
-		return d.Error("[Blaze decodeArray()] max depth reached")
+		return d.DepthError("[Blaze decodeArray()] max depth reached")
 	}
 	i := -1
 	for {
@@ -96,9 +97,7 @@ func (d *Decoder) decodeArray(v reflect.Value, elemDecoder DecoderFn) error {
 			i++
 			d.pos++
 			if i < v.Len() {
-				d.SkipWhitespace()
-				err := elemDecoder(d, v.Index(i))
-				if err != nil {
+				if err := d.decodeIndex(elemDecoder, v, i, v.Len()); err != nil {
 					return err
 				}
 			} else {
@@ -110,14 +109,19 @@ func (d *Decoder) decodeArray(v reflect.Value, elemDecoder DecoderFn) error {
 		case ']':
 			d.pos++
 			d.depth--
+			if d.mergePatch {
+				// Merge patch replaces arrays as a whole, so elements missing in the input are zeroed.
+				for i++; i < v.Len(); i++ {
+					v.Index(i).SetZero()
+				}
+			}
 			return nil
 		case TERMINATION_CHAR:
 			return d.Error("[Blaze decodeArray()] unexpected end of input, expected ']'")
 		default:
 			i++
 			if i < v.Len() {
-				err := elemDecoder(d, v.Index(i))
-				if err != nil {
+				if err := d.decodeIndex(elemDecoder, v, i, v.Len()); err != nil {
 					return err
 				}
 			} else {
@@ -16,22 +16,37 @@ func (d *Decoder) decodeSlice(v reflect.Value, elemDecoder DecoderFn) error {
 		if err != nil {
 			return err
 		}
+		if d.tracking() {
+			if err := d.removeSliceChanges(v, 0); err != nil {
+				return err
+			}
+		}
 		v.SetZero()
 		return nil
 	default:
-		return d.Error("[Blaze decodeSlice()] invalid char, expected '[' or 'null'")
+		return d.mismatchError("[Blaze decodeSlice()] invalid char, expected '[' or 'null'")
 	}
 	d.depth++
 	if d.depth > MAX_DEPTH {
-		return d.Error("[Blaze decodeSlice()] maximum depth reached")
+		return d.DepthError("[Blaze decodeSlice()] maximum depth reached")
 	}
 	size, err := d.ScanArray()
 	if err != nil {
 		return err
 	}
+	oldLen := v.Len()
+	if d.tracking() && size < oldLen {
+		if err := d.removeSliceChanges(v, size); err != nil {
+			return err
+		}
+	}
 	if size == 0 {
+		// Nil slices are kept nil, so empty arrays don't allocate.
+		if !v.IsNil() {
+			v.SetLen(0)
+		}
+		d.depth--
 		return nil
-
 	}
 	cap := v.Cap()
 	if cap < size {
@@ -47,9 +62,7 @@ func (d *Decoder) decodeSlice(v reflect.Value, elemDecoder DecoderFn) error {
 		case ',':
 			i++
 			d.pos++
-			d.SkipWhitespace()
-			err := elemDecoder(d, v.Index(i))
-			if err != nil {
+			if err := d.decodeIndex(elemDecoder, v, i, oldLen); err != nil {
 				return err
 			}
 		case ']':
@@ -60,8 +73,7 @@ func (d *Decoder) decodeSlice(v reflect.Value, elemDecoder DecoderFn) error {
 			return d.Error("[Blaze decodeSlice()] unexpected end of input, expected ']'")
 		default:
 			i++
-			err := elemDecoder(d, v.Index(i))
-			if err != nil {
+			if err := d.decodeIndex(elemDecoder, v, i, oldLen); err != nil {
 				return err
 			}
 		}
@@ -85,7 +97,7 @@ func decodeBytes(d *Decoder, v reflect.Value) error {
 	d.SkipWhitespace()
 	d.start = d.pos
 	if d.char() != '"' {
-		return d.Error("[Blaze decodeBytes()] expected '\"'")
+		return d.mismatchError("[Blaze decodeBytes()] expected '\"'")
 	}
 	d.pos++
 	b, err := d.unquoteString()
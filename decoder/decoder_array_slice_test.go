@@ -41,3 +41,40 @@ func TestDecode_Slice_Struct(t *testing.T) {
 	require.Equal(t, "test2", s[1].Name)
 	require.Equal(t, 20, s[1].Age)
 }
+
+func TestDecode_Slice_Whitespace(t *testing.T) {
+	var s []int
+	err := DDecoder.Unmarshal([]byte("[ 1 ,\n\t2 ]"), &s)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, s)
+	err = DDecoder.Unmarshal([]byte("[ ]"), &s)
+	require.NoError(t, err)
+	require.Empty(t, s)
+	require.NotNil(t, s)
+}
+
+func TestDecode_Slice_Empty(t *testing.T) {
+	// Nil slices stay nil, non-nil slices are truncated.
+	var s []int
+	require.NoError(t, DDecoder.Unmarshal([]byte("[]"), &s))
+	require.Nil(t, s)
+	s = []int{1, 2}
+	require.NoError(t, DDecoder.Unmarshal([]byte("[]"), &s))
+	require.Equal(t, []int{}, s)
+	require.Equal(t, 2, cap(s))
+}
+
+func TestScanArray_Whitespace(t *testing.T) {
+	for data, size := range map[string]int{
+		"[ 1 , [2, 3] ,\n {\"a\":[4]} ]": 3,
+		"[1,2]":                          2,
+		"[ ]":                            0,
+	} {
+		d := DDecoder.NewDecoder([]byte(data))
+		n, err := d.ScanArray()
+		require.NoError(t, err, data)
+		require.Equal(t, size, n, data)
+		require.Equal(t, int64(len(data)), d.pos, data)
+		d.Release()
+	}
+}
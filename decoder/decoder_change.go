@@ -0,0 +1,294 @@
+package decoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/deveox/blaze/encoder"
+	"github.com/deveox/blaze/types"
+)
+
+// Change is a field or an element of a collection, which value has been changed by decoding.
+type Change struct {
+	// Path is a dot-separated path to the value, e.g. "address.city", "tags.3" or "items.2.qty".
+	Path string
+	// Old is the value before decoding. It's nil if the value didn't exist, e.g. a new element of a slice.
+	Old json.RawMessage
+	// New is the value after decoding. It's nil if the value has been removed, e.g. an element of a shortened slice.
+	New json.RawMessage
+}
+
+// changesEncoder returns the encoder of change values. Values are encoded in the same context as they are decoded.
+func (c *Config) changesEncoder() *encoder.Config {
+	c.changesOnce.Do(func() {
+		c.changes = &encoder.Config{Scope: c.Scope, Policies: c.Policies}
+	})
+	return c.changes
+}
+
+func (d *Decoder) unmarshalWithChangeSet(v any, changes *ChangeSet) error {
+	changes.Reset()
+	d.changes = changes
+	err := d.unmarshal(v)
+	d.changes = nil
+	return err
+}
+
+func (d *Decoder) unmarshalWithChanges(v any) ([]string, error) {
+	err := d.unmarshalWithChangeSet(v, &d.changeSet)
+	return d.changeSet.Paths(), err
+}
+
+func (d *Decoder) unmarshalWithChangeValues(v any) ([]Change, error) {
+	d.ChangeValues = make([]Change, 0, 10)
+	err := d.unmarshalWithChangeSet(v, &d.changeSet)
+	changes := d.ChangeValues
+	d.ChangeValues = nil
+	return changes, err
+}
+
+// tracking reports whether the decoder records changes, see [ChangeSet].
+func (d *Decoder) tracking() bool {
+	return d.changes != nil
+}
+
+// trackValues reports whether the decoder records [Change] values of the current value.
+// It's false inside a value, which is tracked as a whole.
+func (d *Decoder) trackValues() bool {
+	return d.ChangeValues != nil && !d.wholeValue
+}
+
+// trackedByParts reports whether changes of the value are tracked by its fields or elements, rather than as a whole.
+// Values set to null, nil pointers and values decoded by custom unmarshalers are tracked as a whole.
+func (d *Decoder) trackedByParts(v reflect.Value) bool {
+	if d.char() == 'n' {
+		return false
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	if hasCustomDecoder(v.Type()) {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
+
+// change is a state of tracking of a single field or element, see [Decoder.beginChange].
+type change struct {
+	parent int32
+	node   int32
+	old    json.RawMessage
+	whole  bool
+	// hidden is set for fields, which can't be read, so their values aren't recorded, see [Decoder.beginFieldChange].
+	hidden bool
+}
+
+func fieldNode(f *types.StructField) changeNode {
+	return changeNode{field: f, index: -1}
+}
+
+// keyNode copies the map key into the change set, so the key can point to the reused buffer of the decoder.
+func (d *Decoder) keyNode(key string) changeNode {
+	start := len(d.changes.keys)
+	d.changes.keys = append(d.changes.keys, key...)
+	return changeNode{keyStart: int32(start), keyEnd: int32(len(d.changes.keys)), index: -1}
+}
+
+func indexNode(i int) changeNode {
+	return changeNode{index: i}
+}
+
+// addChange adds the node as a child of the value being decoded and returns its index.
+func (d *Decoder) addChange(n changeNode) int32 {
+	n.parent = d.changeParent
+	d.changes.nodes = append(d.changes.nodes, n)
+	return int32(len(d.changes.nodes) - 1)
+}
+
+// beginChange starts tracking of the field or element, which is going to be decoded.
+// old is the value before decoding, it's invalid if the value doesn't exist yet, e.g. a new map key.
+// If byParts is true, the changes of the value are tracked by its own fields or elements, otherwise the value is tracked as a whole.
+func (d *Decoder) beginChange(n changeNode, old reflect.Value, byParts bool) (change, error) {
+	c := change{parent: d.changeParent, node: d.addChange(n)}
+	d.changeParent = c.node
+	if d.trackValues() && (!byParts || !old.IsValid()) {
+		c.whole = true
+		if old.IsValid() {
+			var err error
+			if c.old, err = d.encodeChangeValue(old); err != nil {
+				return c, err
+			}
+		}
+		d.wholeValue = true
+	}
+	return c, nil
+}
+
+// beginFieldChange starts tracking of the struct field like [Decoder.beginChange].
+// Values of fields, which can't be read in the context of the decoder (e.g. write-only passwords), aren't recorded,
+// otherwise they would leak into [Change] values. Policies of the field are already checked by decoding.
+func (d *Decoder) beginFieldChange(f *types.StructField, old reflect.Value, byParts bool) (change, error) {
+	if !d.trackValues() || f.Field.CheckEncoderScope(d.config.Scope) {
+		return d.beginChange(fieldNode(f), old, byParts)
+	}
+	c := change{parent: d.changeParent, node: d.addChange(fieldNode(f)), whole: true, hidden: true}
+	d.changeParent = c.node
+	d.wholeValue = true
+	return c, nil
+}
+
+// endChange finishes tracking of the value started by [Decoder.beginChange]. v is the value after decoding.
+func (d *Decoder) endChange(c change, v reflect.Value) error {
+	d.changeParent = c.parent
+	if !c.whole {
+		return nil
+	}
+	d.wholeValue = false
+	if c.hidden {
+		return nil
+	}
+	n, err := d.encodeChangeValue(v)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(c.old, n) {
+		d.ChangeValues = append(d.ChangeValues, Change{Path: d.changes.Path(int(c.node)), Old: c.old, New: n})
+	}
+	return nil
+}
+
+// removeChange records removal of the element, e.g. an element of a shortened slice.
+func (d *Decoder) removeChange(n changeNode, old reflect.Value) error {
+	i := d.addChange(n)
+	if d.trackValues() {
+		o, err := d.encodeChangeValue(old)
+		if err != nil {
+			return err
+		}
+		d.ChangeValues = append(d.ChangeValues, Change{Path: d.changes.Path(int(i)), Old: o})
+	}
+	return nil
+}
+
+// removeSliceChanges records removal of the elements of the slice starting from the given index.
+func (d *Decoder) removeSliceChanges(v reflect.Value, from int) error {
+	for i := from; i < v.Len(); i++ {
+		if err := d.removeChange(indexNode(i), v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeMapChanges records removal of all keys of the map, which is set to null. Keys are reported in sorted order.
+func (d *Decoder) removeMapChanges(v reflect.Value) error {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]reflect.Value, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		k := mapKeyString(iter.Key())
+		keys = append(keys, k)
+		values[k] = iter.Value()
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if err := d.removeChange(d.keyNode(k), values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mapKeyString(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	return fmt.Sprint(k.Interface())
+}
+
+// hasCustomDecoder reports whether the type is decoded by an unmarshaler, see [newDecoderFn].
+func hasCustomDecoder(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	for _, i := range []reflect.Type{tokenUnmarshaler, unmarshaler, stdUnmarshaler, textUnmarshaler} {
+		if t.Implements(i) || ptr.Implements(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeIndex decodes the element of a slice or an array at the given index, see [Decoder.decodeIndexValue].
+// The index is prepended to the paths of the errors of the element.
+func (d *Decoder) decodeIndex(fn DecoderFn, v reflect.Value, i, oldLen int) error {
+	n := len(d.fieldErrors)
+	if err := d.decodeIndexValue(fn, v, i, oldLen); err != nil {
+		return withIndex(err, i)
+	}
+	if len(d.fieldErrors) > n {
+		d.indexFieldErrors(n, i)
+	}
+	return nil
+}
+
+// decodeIndexValue decodes the element at the given index and tracks its changes.
+// Elements starting from oldLen didn't exist before decoding.
+func (d *Decoder) decodeIndexValue(fn DecoderFn, v reflect.Value, i, oldLen int) error {
+	d.SkipWhitespace()
+	elem := v.Index(i)
+	if !d.tracking() {
+		if d.mergePatch {
+			// Merge patch replaces arrays as a whole, so old elements aren't merged.
+			elem.SetZero()
+		}
+		return d.decodeElem(fn, elem)
+	}
+	old := elem
+	if i >= oldLen {
+		old = reflect.Value{}
+	}
+	ch, err := d.beginChange(indexNode(i), old, d.trackedByParts(elem))
+	if err != nil {
+		return err
+	}
+	if d.mergePatch {
+		elem.SetZero()
+	}
+	if err := d.decodeElem(fn, elem); err != nil {
+		return err
+	}
+	return d.endChange(ch, elem)
+}
+
+// encodeChangeValue encodes the value of a field for a [Change].
+func (d *Decoder) encodeChangeValue(v reflect.Value) (json.RawMessage, error) {
+	c := d.config.changesEncoder()
+	e := c.NewEncoder()
+	ctx := e.Ctx
+	e.Ctx = d.Ctx
+	defer func() {
+		e.Ctx = ctx
+		c.Return(e)
+	}()
+	// Encode addressable values by pointer, so marshalers with pointer receivers are used.
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	if err := e.Encode(v.Interface()); err != nil {
+		return nil, err
+	}
+	// The encoder buffer is reused, so the bytes must be copied.
+	return bytes.Clone(e.Bytes()), nil
+}
@@ -0,0 +1,152 @@
+package decoder
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/deveox/blaze/types"
+)
+
+// ChangeSet is a compact tree of values changed by decoding.
+// Nodes are struct fields, map keys and collection indexes, paths are materialized only when they are queried.
+// A ChangeSet can be reused for multiple decodings to avoid allocations, see [Config.UnmarshalWithChangeSet].
+type ChangeSet struct {
+	nodes []changeNode
+	// keys stores map keys of nodes, so decoding doesn't allocate a string for every key.
+	keys []byte
+	buf  []byte
+}
+
+// changeNode is a single segment of a change path: a struct field, a map key or an index.
+type changeNode struct {
+	field *types.StructField
+	// keyStart and keyEnd are the bounds of a map key in [ChangeSet.keys].
+	keyStart, keyEnd int32
+	// index is an index in a slice or an array, or -1.
+	index int
+	// parent is an index of the parent node in [ChangeSet.nodes], or -1 for top level values.
+	parent int32
+}
+
+// Len returns the number of changes.
+func (c *ChangeSet) Len() int {
+	return len(c.nodes)
+}
+
+// Reset removes all changes, keeping the allocated memory.
+func (c *ChangeSet) Reset() {
+	clear(c.nodes)
+	c.nodes = c.nodes[:0]
+	c.keys = c.keys[:0]
+}
+
+// key returns the map key of the node, which is valid until the change set is reset.
+func (c *ChangeSet) key(n *changeNode) []byte {
+	return c.keys[n.keyStart:n.keyEnd]
+}
+
+// Field returns the struct field of the i-th change, or nil if the change is a map key or an element of a collection.
+func (c *ChangeSet) Field(i int) *types.StructField {
+	return c.nodes[i].field
+}
+
+// Path returns the dot-separated path of the i-th change, e.g. "items.2.qty".
+func (c *ChangeSet) Path(i int) string {
+	return string(c.AppendPath(nil, i))
+}
+
+// AppendPath appends the path of the i-th change to dst and returns the extended buffer.
+func (c *ChangeSet) AppendPath(dst []byte, i int) []byte {
+	start := len(dst)
+	// Append segments from the leaf to the root and reverse them.
+	for n := int32(i); n >= 0; n = c.nodes[n].parent {
+		if len(dst) > start {
+			dst = append(dst, '.')
+		}
+		segStart := len(dst)
+		dst = c.appendSegment(dst, &c.nodes[n])
+		reverse(dst[segStart:])
+	}
+	reverse(dst[start:])
+	return dst
+}
+
+// Each calls fn for every change in the order of decoding until fn returns false.
+// The path is valid only during the call, use [string] to keep it.
+func (c *ChangeSet) Each(fn func(path []byte) bool) {
+	for i := range c.nodes {
+		c.buf = c.AppendPath(c.buf[:0], i)
+		if !fn(c.buf) {
+			return
+		}
+	}
+}
+
+// Has reports whether the value with the given dot-separated path has been changed.
+func (c *ChangeSet) Has(path string) bool {
+	for i := range c.nodes {
+		if c.matches(int32(i), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Paths returns the paths of all changes in the order of decoding, like [Config.UnmarshalWithChanges].
+func (c *ChangeSet) Paths() []string {
+	res := make([]string, len(c.nodes))
+	for i := range c.nodes {
+		c.buf = c.AppendPath(c.buf[:0], i)
+		res[i] = string(c.buf)
+	}
+	return res
+}
+
+// matches compares the path of the node with the given path segment by segment, starting from the leaf.
+func (c *ChangeSet) matches(n int32, path string) bool {
+	for ; n >= 0; n = c.nodes[n].parent {
+		seg := path
+		if i := strings.LastIndexByte(path, '.'); i >= 0 {
+			seg = path[i+1:]
+			path = path[:i]
+		} else {
+			path = ""
+		}
+		if !c.is(&c.nodes[n], seg) {
+			return false
+		}
+		if path == "" {
+			return c.nodes[n].parent < 0
+		}
+	}
+	return false
+}
+
+func (c *ChangeSet) is(n *changeNode, seg string) bool {
+	switch {
+	case n.field != nil:
+		return n.field.Field.Name == seg
+	case n.index >= 0:
+		i, err := strconv.Atoi(seg)
+		return err == nil && i == n.index
+	default:
+		return string(c.key(n)) == seg
+	}
+}
+
+func (c *ChangeSet) appendSegment(dst []byte, n *changeNode) []byte {
+	switch {
+	case n.field != nil:
+		return append(dst, n.field.Field.Name...)
+	case n.index >= 0:
+		return strconv.AppendInt(dst, int64(n.index), 10)
+	default:
+		return append(dst, c.key(n)...)
+	}
+}
+
+func reverse(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
@@ -19,5 +19,5 @@ func decodeCustom(d *Decoder, v reflect.Value) error {
 		return err
 	}
 	u := v.Interface().(Unmarshaler)
-	return u.UnmarshalBlaze(d, d.Buf[d.start:d.pos])
+	return d.unmarshalerError(u.UnmarshalBlaze(d, d.Buf[d.start:d.pos]), d.start)
 }
@@ -0,0 +1,177 @@
+package decoder
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Reason is a reason of a [FieldError].
+type Reason int
+
+const (
+	// The key doesn't match any field of the struct.
+	REASON_UNKNOWN Reason = iota
+	// The field exists, but it can't be decoded in the context and operation of the decoder.
+	REASON_FORBIDDEN
+	// The field is required for the operation of the decoder, but it's missing or null.
+	REASON_REQUIRED
+	// The value doesn't pass a validation rule of the field, see [types.Rule].
+	REASON_INVALID
+	// The value can't be decoded into the type of the field, see [Config.CollectErrors].
+	REASON_TYPE
+)
+
+func (r Reason) String() string {
+	switch r {
+	case REASON_UNKNOWN:
+		return "unknown"
+	case REASON_FORBIDDEN:
+		return "forbidden"
+	case REASON_REQUIRED:
+		return "required"
+	case REASON_INVALID:
+		return "invalid"
+	case REASON_TYPE:
+		return "type"
+	default:
+		return "invalid reason"
+	}
+}
+
+// FieldError describes a problem with a single value in the input, which doesn't stop decoding.
+type FieldError struct {
+	// Path is a JSON path to the value, e.g. "items[3].address.zip".
+	Path    string
+	Reason  Reason
+	Message string
+	// Type is the Go type of the value, which was expected. It's set only for [REASON_TYPE].
+	Type string
+	// Raw is the raw JSON value from the input. It's set only for [REASON_TYPE].
+	Raw string
+	// Err is the underlying error, e.g. [*TypeError] for [REASON_TYPE] or [*ScopeError] for [REASON_FORBIDDEN].
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors is returned when decoding finished, but one or more [FieldError] were collected,
+// e.g. in [Config.Strict] mode or when required fields are missing.
+type FieldErrors struct {
+	Errors []*FieldError
+}
+
+func (e *FieldErrors) Error() string {
+	var sb strings.Builder
+	sb.WriteString("[Blaze Unmarshal()] ")
+	sb.WriteString(strconv.Itoa(len(e.Errors)))
+	sb.WriteString(" field error(s): ")
+	for i, fe := range e.Errors {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(fe.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns the collected errors, so [errors.As] can be used to find a [*FieldError].
+func (e *FieldErrors) Unwrap() []error {
+	res := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		res[i] = fe
+	}
+	return res
+}
+
+// prependKey prepends an object key to the path relative to the object.
+// The key is cloned, because keys point into the reused buffer of the decoder.
+func prependKey(path, key string) string {
+	switch {
+	case path == "":
+		return strings.Clone(key)
+	case path[0] == '[':
+		return key + path
+	default:
+		return key + "." + path
+	}
+}
+
+// prependIndex prepends an array index to the path relative to the element.
+func prependIndex(path string, i int) string {
+	idx := "[" + strconv.Itoa(i) + "]"
+	if path == "" || path[0] == '[' {
+		return idx + path
+	}
+	return idx + "." + path
+}
+
+// withKey prepends an object key to the path of the error.
+// Paths are built while errors are returned, so decoding of valid values doesn't pay for them.
+func withKey(err error, key string) error {
+	var e *Error
+	if errors.As(err, &e) {
+		e.Path = prependKey(e.Path, key)
+	}
+	return err
+}
+
+// withIndex prepends an array index to the path of the error.
+func withIndex(err error, i int) error {
+	var e *Error
+	if errors.As(err, &e) {
+		e.Path = prependIndex(e.Path, i)
+	}
+	return err
+}
+
+// keyFieldErrors prepends an object key to the paths of the field errors collected since the n-th one.
+func (d *Decoder) keyFieldErrors(n int, key string) {
+	for _, fe := range d.fieldErrors[n:] {
+		fe.Path = prependKey(fe.Path, key)
+		withKey(fe.Err, key)
+	}
+}
+
+// indexFieldErrors prepends an array index to the paths of the field errors collected since the n-th one.
+func (d *Decoder) indexFieldErrors(n int, i int) {
+	for _, fe := range d.fieldErrors[n:] {
+		fe.Path = prependIndex(fe.Path, i)
+		withIndex(fe.Err, i)
+	}
+}
+
+// addFieldError records a [FieldError] for the value being decoded. The path is relative to the value, see [withKey].
+func (d *Decoder) addFieldError(reason Reason, msg string) {
+	d.fieldErrors = append(d.fieldErrors, &FieldError{
+		Reason:  reason,
+		Message: msg,
+	})
+}
+
+// decodeElem decodes an element of a collection, see [Decoder.valueError] for the error handling.
+func (d *Decoder) decodeElem(fn DecoderFn, v reflect.Value) error {
+	d.SkipWhitespace()
+	start, depth := d.pos, d.depth
+	if err := fn(d, v); err != nil {
+		return d.valueError(err, v, start, depth, nil, nil)
+	}
+	return nil
+}
+
+// fieldErrorsResult returns the collected field errors as a single error, or nil if there are none.
+func (d *Decoder) fieldErrorsResult() error {
+	if len(d.fieldErrors) == 0 {
+		return nil
+	}
+	errs := make([]*FieldError, len(d.fieldErrors))
+	copy(errs, d.fieldErrors)
+	return &FieldErrors{Errors: errs}
+}
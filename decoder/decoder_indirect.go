@@ -1,6 +1,9 @@
 package decoder
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+)
 
 func newIfAddressable(then, otherwise DecoderFn) DecoderFn {
 	return func(d *Decoder, v reflect.Value) error {
@@ -13,7 +16,7 @@ func newIfAddressable(then, otherwise DecoderFn) DecoderFn {
 
 func decodeInterface(d *Decoder, v reflect.Value) error {
 	if v.IsNil() {
-		return d.ErrorF("[Blaze decodeInterface()] cannot decode into nil interface '%s'", v.Type())
+		return d.UnsupportedTypeError(fmt.Sprintf("[Blaze decodeInterface()] cannot decode into nil interface '%s'", v.Type()), v.Type())
 	}
 	return d.decode(v.Elem())
 }
@@ -21,15 +24,27 @@ func decodeInterface(d *Decoder, v reflect.Value) error {
 func decodePtr(d *Decoder, v reflect.Value) error {
 	c := d.char()
 	if c == 'n' {
+		start := d.pos
 		err := d.ScanNull()
 		if err != nil {
 			return err
 		}
 		if v.CanSet() {
+			if d.mergePatch && !v.IsNil() && v.Type().Elem().Kind() == reflect.Struct && !hasCustomDecoder(v.Type().Elem()) {
+				// Merge patch zeroes only the fields, which can be decoded in the scope, and removes the struct if nothing is left.
+				d.pos = start
+				if err := d.decode(v.Elem()); err != nil {
+					return err
+				}
+				if v.Elem().IsZero() {
+					v.SetZero()
+				}
+				return nil
+			}
 			v.SetZero()
 			return nil
 		}
-		d.pos -= 4
+		d.pos = start
 	}
 	if v.IsNil() {
 		v.Set(reflect.New(v.Type().Elem()))
@@ -38,5 +53,5 @@ func decodePtr(d *Decoder, v reflect.Value) error {
 }
 
 func decodeInvalid(d *Decoder, v reflect.Value) error {
-	return d.ErrorF("[Blaze decodeInvalid()] cannot decode to unsupported type '%s'", v.Type())
+	return d.UnsupportedTypeError(fmt.Sprintf("[Blaze decodeInvalid()] cannot decode to unsupported type '%s'", v.Type()), v.Type())
 }
@@ -0,0 +1,611 @@
+package decoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/deveox/blaze/ctx"
+	"github.com/deveox/blaze/scopes"
+	"github.com/deveox/blaze/types"
+)
+
+// ErrPatchTest is returned when a "test" operation of a JSON Patch fails, see [Config.ApplyJSONPatch].
+var ErrPatchTest = errors.New("[Blaze] patch test failed")
+
+// PatchError is returned when an operation of a JSON Patch can't be applied. The target isn't changed in this case.
+// Err is the cause, e.g. [ErrNotFound], [ErrPatchTest], [*ScopeError] or a decoding error of the value.
+type PatchError struct {
+	// Index is the index of the failed operation in the patch.
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("[Blaze ApplyJSONPatch()] operation %d (%s %s): %s", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// patchConfig decodes operations of JSON Patches. Options of the config of the patch, e.g. [Config.Strict], apply only to values of operations.
+var patchConfig = &Config{}
+
+// patchOperation is an operation of a JSON Patch, see RFC 6902.
+type patchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from"`
+	Value json.RawMessage
+}
+
+// ApplyJSONPatch applies the JSON Patch (RFC 6902) to the target with the given scope and returns the changes like [Config.UnmarshalWithChanges].
+// Paths of operations are JSON Pointers (RFC 6901) resolved through struct fields, map keys and slice and array indexes.
+// Every struct field changed by an operation must be decodable in the scope, and every field read by "copy", "move" and "test" must be encodable.
+//
+// The patch is applied to a deep copy of the target, which replaces the target only if all operations succeed, so a failed patch doesn't change it.
+// Values are decoded like by [Config.UnmarshalScoped], but replace old values as a whole. Fields, which can't be decoded in the scope, are kept.
+// Values of "copy" and "move" are copied through their JSON representation in the scope.
+// "test" decodes its value into the type of the target value and compares it with the target value as it's seen in the scope,
+// so omitted empty fields and fields, which can't be decoded in the scope, don't make the test fail.
+// The target can't contain cyclic pointers.
+func (c *Config) ApplyJSONPatch(target any, ops []byte, operation scopes.Decoding) ([]string, error) {
+	t := c.NewDecoder(ops)
+	defer c.decoderPool.Put(t)
+	t.operation = operation
+	t.Ctx.Clear()
+	return t.applyJSONPatch(target)
+}
+
+// ApplyJSONPatchCtx sets the [*ctx.Ctx] and applies the JSON Patch to the target with the given scope, see [Config.ApplyJSONPatch].
+func (c *Config) ApplyJSONPatchCtx(target any, ops []byte, operation scopes.Decoding, ctx *ctx.Ctx) ([]string, error) {
+	t := c.NewDecoder(ops)
+	defer c.decoderPool.Put(t)
+	t.operation = operation
+	t.Ctx = ctx
+	return t.applyJSONPatch(target)
+}
+
+func (d *Decoder) applyJSONPatch(target any) ([]string, error) {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return nil, d.UnsupportedTypeError(fmt.Sprintf("[Blaze ApplyJSONPatch()] can't apply patch to non-pointer value '%T'", target), reflect.TypeOf(target))
+	}
+	var ops []patchOperation
+	od := patchConfig.NewDecoder(d.Buf[:len(d.Buf)-1])
+	defer od.Release()
+	if err := od.unmarshal(&ops); err != nil {
+		return nil, err
+	}
+	root := reflect.New(rv.Elem().Type()).Elem()
+	copyValue(root, rv.Elem())
+	d.changeSet.Reset()
+	d.changes = &d.changeSet
+	defer func() { d.changes = nil }()
+	for i, op := range ops {
+		if err := d.applyPatchOperation(root, &op); err != nil {
+			return nil, &PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	if err := d.fieldErrorsResult(); err != nil {
+		return nil, err
+	}
+	rv.Elem().Set(root)
+	// Operations can change the same values several times, e.g. a field is zeroed and then decoded by "replace".
+	paths := d.changeSet.Paths()
+	seen := make(map[string]bool, len(paths))
+	res := paths[:0]
+	for _, p := range paths {
+		if !seen[p] {
+			seen[p] = true
+			res = append(res, p)
+		}
+	}
+	return res, nil
+}
+
+func (d *Decoder) applyPatchOperation(root reflect.Value, op *patchOperation) error {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case "add", "replace":
+		if op.Value == nil {
+			return errors.New("[Blaze ApplyJSONPatch()] missing value")
+		}
+		return d.patchSet(root, path, op.Value, op.Op == "add")
+	case "remove":
+		return d.patchRemove(root, path)
+	case "copy", "move":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return err
+		}
+		if op.Op == "move" && len(from) < len(path) && isPrefix(path, from) {
+			return errors.New("[Blaze ApplyJSONPatch()] can't move a value into itself")
+		}
+		value, _, err := d.patchGet(root, from)
+		if err != nil {
+			return err
+		}
+		if op.Op == "move" {
+			if err := d.patchRemove(root, from); err != nil {
+				return err
+			}
+		}
+		return d.patchSet(root, path, value, true)
+	case "test":
+		if op.Value == nil {
+			return errors.New("[Blaze ApplyJSONPatch()] missing value")
+		}
+		value, t, err := d.patchGet(root, path)
+		if err != nil {
+			return err
+		}
+		expected, err := d.patchValue(t, op.Value)
+		if err != nil {
+			return err
+		}
+		actual, err := d.patchValue(t, value)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(expected.Interface(), actual.Interface()) {
+			return ErrPatchTest
+		}
+		return nil
+	default:
+		return errors.New("[Blaze ApplyJSONPatch()] unknown operation: " + op.Op)
+	}
+}
+
+// patchGet returns the JSON representation and the type of the value at the path.
+func (d *Decoder) patchGet(root reflect.Value, path []string) ([]byte, reflect.Type, error) {
+	if len(path) == 0 {
+		res, err := d.encodeChangeValue(root)
+		return res, root.Type(), err
+	}
+	d.resetPatchPath()
+	var res []byte
+	var t reflect.Type
+	err := d.walkPatch(root, path, false, func(v reflect.Value, token string) error {
+		elem, err := d.patchElem(v, token, false)
+		if err != nil {
+			return err
+		}
+		t = elem.Type()
+		res, err = d.encodeChangeValue(elem)
+		return err
+	})
+	return res, t, err
+}
+
+// patchValue decodes the JSON representation into a new value of the type in the scope.
+// Field errors, e.g. of fields which can't be decoded in the scope, are ignored, the fields are left empty.
+func (d *Decoder) patchValue(t reflect.Type, value []byte) (reflect.Value, error) {
+	v := reflect.New(t)
+	nd := d.Decoder(value)
+	defer nd.Release()
+	if err := nd.decodeValue(v.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return v.Elem(), nil
+}
+
+// patchSet sets the value at the path. If add is true, the value is inserted into slices and can create map keys.
+func (d *Decoder) patchSet(root reflect.Value, path []string, value []byte, add bool) error {
+	d.resetPatchPath()
+	if len(path) == 0 {
+		return d.decodeReplace(root, value)
+	}
+	return d.walkPatch(root, path, true, func(v reflect.Value, token string) error {
+		switch v.Kind() {
+		case reflect.Map:
+			key, err := mapKey(v.Type().Key(), token)
+			if err != nil {
+				return err
+			}
+			old := v.MapIndex(key)
+			if !add && !old.IsValid() {
+				return ErrNotFound
+			}
+			d.pushPatchKey(token)
+			d.changeParent = d.addChange(d.keyNode(token))
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if old.IsValid() {
+				elem.Set(old)
+			}
+			if err := d.decodeReplace(elem, value); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+			return nil
+		case reflect.Slice:
+			if !add {
+				break
+			}
+			n := v.Len()
+			i := n
+			if token != "-" {
+				var err error
+				if i, err = patchIndex(token, n+1); err != nil {
+					return err
+				}
+			}
+			v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			reflect.Copy(v.Slice(i+1, n+1), v.Slice(i, n))
+			v.Index(i).SetZero()
+			// The inserted element shifts the following ones.
+			for j := i + 1; j <= n; j++ {
+				d.addChange(indexNode(j))
+			}
+			d.pushPatchIndex(i)
+			d.changeParent = d.addChange(indexNode(i))
+			return d.decodeReplace(v.Index(i), value)
+		case reflect.Array:
+			if add {
+				return errors.New("[Blaze ApplyJSONPatch()] can't add an element to an array")
+			}
+		}
+		elem, err := d.patchElem(v, token, true)
+		if err != nil {
+			return err
+		}
+		return d.decodeReplace(elem, value)
+	})
+}
+
+// patchRemove removes the value at the path. Struct fields are zeroed, keeping the fields which can't be decoded in the scope.
+func (d *Decoder) patchRemove(root reflect.Value, path []string) error {
+	if len(path) == 0 {
+		return errors.New("[Blaze ApplyJSONPatch()] can't remove the root value")
+	}
+	d.resetPatchPath()
+	return d.walkPatch(root, path, true, func(v reflect.Value, token string) error {
+		switch v.Kind() {
+		case reflect.Map:
+			key, err := mapKey(v.Type().Key(), token)
+			if err != nil {
+				return err
+			}
+			if !v.MapIndex(key).IsValid() {
+				return ErrNotFound
+			}
+			d.addChange(d.keyNode(token))
+			v.SetMapIndex(key, reflect.Value{})
+			return nil
+		case reflect.Slice:
+			n := v.Len()
+			i, err := patchIndex(token, n)
+			if err != nil {
+				return err
+			}
+			reflect.Copy(v.Slice(i, n-1), v.Slice(i+1, n))
+			v.Index(n - 1).SetZero()
+			v.SetLen(n - 1)
+			// The removed element shifts the following ones.
+			for j := i; j < n; j++ {
+				d.addChange(indexNode(j))
+			}
+			return nil
+		case reflect.Array:
+			return errors.New("[Blaze ApplyJSONPatch()] can't remove an element of an array")
+		}
+		elem, err := d.patchElem(v, token, true)
+		if err != nil {
+			return err
+		}
+		return d.decodeReplace(elem, []byte("null"))
+	})
+}
+
+// walkPatch resolves the container of the last token of the path and calls fn with it.
+// Maps and interfaces hold values, which can't be changed in place, so their values are copied and set back after fn.
+// If write is true, the changes of the values along the path are tracked and struct fields must be decodable in the scope.
+func (d *Decoder) walkPatch(v reflect.Value, path []string, write bool, fn func(v reflect.Value, token string) error) error {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ErrNotFound
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ErrNotFound
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := d.walkPatch(elem, path, write, fn); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if len(path) == 1 {
+		return fn(v, path[0])
+	}
+	token := path[0]
+	elem, err := d.patchElem(v, token, write)
+	if err != nil {
+		return err
+	}
+	if v.Kind() != reflect.Map {
+		return d.walkPatch(elem, path[1:], write, fn)
+	}
+	copied := reflect.New(elem.Type()).Elem()
+	copied.Set(elem)
+	if err := d.walkPatch(copied, path[1:], write, fn); err != nil {
+		return err
+	}
+	key, _ := mapKey(v.Type().Key(), token)
+	v.SetMapIndex(key, copied)
+	return nil
+}
+
+// patchElem returns the existing field, map value or element of v by the token, pushes it to the path and tracks its change.
+func (d *Decoder) patchElem(v reflect.Value, token string, write bool) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Struct:
+		si := types.Cache.Get(v.Type())
+		f, ok := si.GetField(token)
+		if !ok {
+			return reflect.Value{}, ErrNotFound
+		}
+		d.pushPatchKey(token)
+		if err := d.checkPatchScope(si, v, f, write); err != nil {
+			return reflect.Value{}, err
+		}
+		if write {
+			d.changeParent = d.addChange(fieldNode(f))
+		}
+		return f.Value(v), nil
+	case reflect.Map:
+		key, err := mapKey(v.Type().Key(), token)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		elem := v.MapIndex(key)
+		if !elem.IsValid() {
+			return reflect.Value{}, ErrNotFound
+		}
+		d.pushPatchKey(token)
+		if write {
+			d.changeParent = d.addChange(d.keyNode(token))
+		}
+		return elem, nil
+	case reflect.Slice, reflect.Array:
+		i, err := patchIndex(token, v.Len())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		d.pushPatchIndex(i)
+		if write {
+			d.changeParent = d.addChange(indexNode(i))
+		}
+		return v.Index(i), nil
+	default:
+		return reflect.Value{}, ErrNotFound
+	}
+}
+
+// checkPatchScope checks that the field can be decoded in the scope if it's written, or encoded otherwise.
+func (d *Decoder) checkPatchScope(si *types.Struct, v reflect.Value, f *types.StructField, write bool) error {
+	ok := d.config.Policies.Check(d.Ctx, si, v, f.Field)
+	var msg string
+	if write {
+		ok = ok && f.Field.CheckDecoderScope(d.config.Scope, d.operation)
+		msg = "[Blaze ApplyJSONPatch()] field can't be decoded in '" + d.config.Scope.Name() + "' context with '" + d.operation.Name() + "' operation"
+	} else {
+		ok = ok && f.Field.CheckEncoderScope(d.config.Scope)
+		msg = "[Blaze ApplyJSONPatch()] field can't be encoded in '" + d.config.Scope.Name() + "' context"
+	}
+	if ok {
+		return nil
+	}
+	return &ScopeError{ErrorInfo: &Error{Message: msg, Path: string(d.patchPath), Type: si.Type, Field: f.Field.TitleCase}, Context: d.config.Scope, Operation: d.operation}
+}
+
+// resetPatchPath starts resolving of a new path from the root value.
+func (d *Decoder) resetPatchPath() {
+	d.patchPath = d.patchPath[:0]
+	d.changeParent = -1
+}
+
+// pushPatchKey appends an object key to the path of the value being resolved.
+func (d *Decoder) pushPatchKey(key string) {
+	if len(d.patchPath) > 0 {
+		d.patchPath = append(d.patchPath, '.')
+	}
+	d.patchPath = append(d.patchPath, key...)
+}
+
+// pushPatchIndex appends an array index to the path of the value being resolved.
+func (d *Decoder) pushPatchIndex(i int) {
+	d.patchPath = append(d.patchPath, '[')
+	d.patchPath = strconv.AppendInt(d.patchPath, int64(i), 10)
+	d.patchPath = append(d.patchPath, ']')
+}
+
+// withPatchPath prepends the path of the value being resolved to the paths of the error and the field errors collected since the n-th one.
+func (d *Decoder) withPatchPath(err error, n int) error {
+	if len(d.patchPath) == 0 {
+		return err
+	}
+	path := string(d.patchPath)
+	d.keyFieldErrors(n, path)
+	return withKey(err, path)
+}
+
+// decodeReplace decodes the value into v replacing the old value as a whole.
+// Structs are zeroed like by decoding null first, so fields which can't be decoded in the scope are kept.
+func (d *Decoder) decodeReplace(v reflect.Value, value []byte) error {
+	nd := d.Decoder(value)
+	defer nd.Release()
+	nd.changes = d.changes
+	nd.changeParent = d.changeParent
+	s := v
+	for s.Kind() == reflect.Pointer && !s.IsNil() {
+		s = s.Elem()
+	}
+	if s.Kind() == reflect.Struct && !hasCustomDecoder(s.Type()) {
+		null := d.Decoder([]byte("null"))
+		null.changes = d.changes
+		null.changeParent = d.changeParent
+		err := null.decode(s)
+		null.Release()
+		if err != nil {
+			return err
+		}
+		// A pointer to a struct with fields, which can't be decoded in the scope, isn't removed.
+		if v.Kind() == reflect.Pointer && string(value) == "null" && !s.IsZero() {
+			return nil
+		}
+	} else if v.Kind() != reflect.Pointer {
+		v.SetZero()
+	}
+	n := len(d.fieldErrors)
+	err := nd.decodeElem(getDecoderFn(v.Type()), v)
+	d.fieldErrors = append(d.fieldErrors, nd.fieldErrors...)
+	if err = d.withPatchPath(err, n); err != nil {
+		return err
+	}
+	nd.SkipWhitespace()
+	if nd.char() != TERMINATION_CHAR {
+		return d.withPatchPath(nd.Error("[Blaze ApplyJSONPatch()] unexpected data after the value"), len(d.fieldErrors))
+	}
+	return nil
+}
+
+// parsePointer splits the JSON Pointer (RFC 6901) into unescaped tokens. An empty pointer refers to the whole value.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, errors.New("[Blaze ApplyJSONPatch()] invalid JSON pointer: " + p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		if strings.IndexByte(t, '~') >= 0 {
+			tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+		}
+	}
+	return tokens, nil
+}
+
+// patchIndex parses the array index of the token, which must be less than n.
+func patchIndex(token string, n int) (int, error) {
+	if token == "-" {
+		// "-" refers to the element after the last one, which never exists.
+		return 0, ErrNotFound
+	}
+	if len(token) > 1 && token[0] == '0' {
+		return 0, errors.New("[Blaze ApplyJSONPatch()] invalid array index: " + token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, errors.New("[Blaze ApplyJSONPatch()] invalid array index: " + token)
+	}
+	if i >= n {
+		return 0, ErrNotFound
+	}
+	return i, nil
+}
+
+// mapKey converts the token to a key of the given type.
+func mapKey(t reflect.Type, token string) (reflect.Value, error) {
+	k := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		k.SetString(token)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(token, 10, t.Bits())
+		if err != nil {
+			return k, errors.New("[Blaze ApplyJSONPatch()] invalid map key: " + token)
+		}
+		k.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(token, 10, t.Bits())
+		if err != nil {
+			return k, errors.New("[Blaze ApplyJSONPatch()] invalid map key: " + token)
+		}
+		k.SetUint(i)
+	default:
+		return k, errors.New("[Blaze ApplyJSONPatch()] unsupported map key type: " + t.String())
+	}
+	return k, nil
+}
+
+func isPrefix(path, prefix []string) bool {
+	for i, t := range prefix {
+		if path[i] != t {
+			return false
+		}
+	}
+	return true
+}
+
+// copyValue deeply copies src into dst, so they don't share pointers, maps and slices.
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		copyValue(dst.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		copyValue(elem, src.Elem())
+		dst.Set(elem)
+	case reflect.Map:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			elem := reflect.New(src.Type().Elem()).Elem()
+			copyValue(elem, iter.Value())
+			m.SetMapIndex(iter.Key(), elem)
+		}
+		dst.Set(m)
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.SetZero()
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			copyValue(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Struct:
+		// Unexported fields can't be set one by one, so they are copied shallowly with the struct.
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if dst.Field(i).CanSet() {
+				copyValue(dst.Field(i), src.Field(i))
+			}
+		}
+	default:
+		dst.Set(src)
+	}
+}
@@ -0,0 +1,182 @@
+package decoder
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/deveox/blaze/scopes"
+	"github.com/stretchr/testify/require"
+)
+
+type PatchAddress struct {
+	City string
+	Zip  string `blaze:"client:read"`
+}
+
+type PatchItem struct {
+	Name string
+	Qty  int
+}
+
+type PatchStruct struct {
+	Title    string
+	Address  *PatchAddress
+	Tags     []string
+	Items    []PatchItem
+	Settings map[string]PatchItem
+	Extra    map[string]any
+	Secret   string `blaze:"client:read"`
+	Hidden   string `blaze:"client:-"`
+}
+
+func newPatchStruct() *PatchStruct {
+	return &PatchStruct{
+		Title:    "old",
+		Address:  &PatchAddress{City: "Paris", Zip: "75001"},
+		Tags:     []string{"a", "b", "c"},
+		Items:    []PatchItem{{Name: "x", Qty: 1}},
+		Settings: map[string]PatchItem{"a": {Name: "a"}},
+		Extra:    map[string]any{"list": []any{1.0}},
+		Secret:   "secret",
+		Hidden:   "hidden",
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	v := newPatchStruct()
+	changes, err := clientDecoder.ApplyJSONPatch(v, []byte(`[
+		{"op": "test", "path": "/title", "value": "old"},
+		{"op": "replace", "path": "/title", "value": "new"},
+		{"op": "replace", "path": "/address", "value": {"city": "Lyon"}},
+		{"op": "add", "path": "/tags/1", "value": "x"},
+		{"op": "remove", "path": "/tags/0"},
+		{"op": "add", "path": "/tags/-", "value": "z"},
+		{"op": "replace", "path": "/items/0/qty", "value": 5},
+		{"op": "add", "path": "/settings/b", "value": {"name": "b"}},
+		{"op": "move", "from": "/settings/a", "path": "/settings/c"},
+		{"op": "copy", "from": "/items/0", "path": "/items/-"},
+		{"op": "add", "path": "/extra/list/0", "value": "first"},
+		{"op": "add", "path": "/extra/a~1b", "value": true}
+	]`), scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+	require.Equal(t, &PatchStruct{
+		Title:    "new",
+		Address:  &PatchAddress{City: "Lyon", Zip: "75001"},
+		Tags:     []string{"x", "b", "c", "z"},
+		Items:    []PatchItem{{Name: "x", Qty: 5}, {Name: "x", Qty: 5}},
+		Settings: map[string]PatchItem{"b": {Name: "b"}, "c": {Name: "a"}},
+		Extra:    map[string]any{"list": []any{"first", 1.0}, "a/b": true},
+		Secret:   "secret",
+		Hidden:   "hidden",
+	}, v)
+	require.Equal(t, []string{
+		"title", "address", "address.city", "tags", "tags.2", "tags.3", "tags.1", "tags.0",
+		"items", "items.0", "items.0.qty", "settings", "settings.b", "settings.b.name", "settings.a", "settings.c", "settings.c.name",
+		"items.1", "items.1.name", "items.1.qty", "extra", "extra.list", "extra.list.1", "extra.list.0", "extra.a/b",
+	}, changes)
+}
+
+func TestApplyJSONPatch_Test(t *testing.T) {
+	v := newPatchStruct()
+	// Empty fields are omitted in the JSON representation of the value, but compared by their decoded values.
+	_, err := clientDecoder.ApplyJSONPatch(v, []byte(`[
+		{"op": "test", "path": "/settings/a", "value": {"name": "a", "qty": 0}},
+		{"op": "test", "path": "/settings/a", "value": {"name": "a"}},
+		{"op": "test", "path": "/address", "value": {"city": "Paris", "zip": "75001"}},
+		{"op": "test", "path": "/tags", "value": ["a", "b", "c"]}
+	]`), scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+
+	for _, ops := range []string{
+		`[{"op": "test", "path": "/settings/a", "value": {"name": "a", "qty": 1}}]`,
+		`[{"op": "test", "path": "/tags", "value": ["a", "b"]}]`,
+		`[{"op": "test", "path": "/address", "value": null}]`,
+	} {
+		_, err := clientDecoder.ApplyJSONPatch(v, []byte(ops), scopes.DECODE_UPDATE)
+		require.ErrorIs(t, err, ErrPatchTest, ops)
+	}
+}
+
+func TestApplyJSONPatch_Atomic(t *testing.T) {
+	cases := []struct {
+		ops string
+		err error
+	}{
+		{`[{"op": "replace", "path": "/title", "value": "new"}, {"op": "test", "path": "/title", "value": "old"}]`, ErrPatchTest},
+		{`[{"op": "replace", "path": "/title", "value": "new"}, {"op": "remove", "path": "/tags/5"}]`, ErrNotFound},
+		{`[{"op": "replace", "path": "/title", "value": "new"}, {"op": "replace", "path": "/secret", "value": "x"}]`, ErrScope},
+		{`[{"op": "replace", "path": "/title", "value": "new"}, {"op": "replace", "path": "/address/zip", "value": "x"}]`, ErrScope},
+		{`[{"op": "copy", "from": "/hidden", "path": "/title"}]`, ErrScope},
+		{`[{"op": "replace", "path": "/title", "value": "new"}, {"op": "replace", "path": "/items/0/qty", "value": "x"}]`, ErrType},
+		{`[{"op": "replace", "path": "/settings/x", "value": {}}]`, ErrNotFound},
+		{`[{"op": "move", "from": "/items", "path": "/items/0"}]`, nil},
+		{`[{"op": "remove", "path": "/tags/-"}]`, ErrNotFound},
+		{`[{"op": "unknown", "path": "/title"}]`, nil},
+	}
+	for _, c := range cases {
+		v := newPatchStruct()
+		_, err := clientDecoder.ApplyJSONPatch(v, []byte(c.ops), scopes.DECODE_UPDATE)
+		var pe *PatchError
+		require.True(t, errors.As(err, &pe), c.ops)
+		if c.err != nil {
+			require.ErrorIs(t, err, c.err, c.ops)
+		}
+		require.Equal(t, newPatchStruct(), v, c.ops)
+	}
+}
+
+func TestApplyJSONPatch_Remove(t *testing.T) {
+	v := newPatchStruct()
+	changes, err := clientDecoder.ApplyJSONPatch(v, []byte(`[
+		{"op": "remove", "path": "/address"},
+		{"op": "remove", "path": "/settings/a"},
+		{"op": "remove", "path": "/title"}
+	]`), scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+	// The address isn't removed, because the client can't change the zip code.
+	require.Equal(t, &PatchAddress{Zip: "75001"}, v.Address)
+	require.Empty(t, v.Settings)
+	require.Empty(t, v.Title)
+	require.Equal(t, []string{"address", "address.city", "settings", "settings.a", "title"}, changes)
+
+	_, err = adminDecoder.ApplyJSONPatch(v, []byte(`[{"op": "remove", "path": "/address"}]`), scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+	require.Nil(t, v.Address)
+
+	// Replacing the root value keeps fields, which the client can't change.
+	changes, err = clientDecoder.ApplyJSONPatch(v, []byte(`[{"op": "replace", "path": "", "value": {"title": "root"}}]`), scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+	require.Equal(t, &PatchStruct{Title: "root", Secret: "secret", Hidden: "hidden"}, v)
+	require.Equal(t, []string{"tags", "items", "settings", "extra", "title"}, changes)
+}
+
+func TestApplyJSONPatch_Strict(t *testing.T) {
+	// Unknown members of operations are ignored (RFC 6902), while values are decoded strictly.
+	v := &StrictStruct{}
+	changes, err := strictDecoder.ApplyJSONPatch(v, []byte(`[{"op":"replace","path":"/name","value":"new","comment":"x"}]`), scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+	require.Equal(t, []string{"name"}, changes)
+	require.Equal(t, "new", v.Name)
+
+	_, err = strictDecoder.ApplyJSONPatch(v, []byte(`[{"op":"replace","path":"/items","value":[{"unknown":1}]}]`), scopes.DECODE_UPDATE)
+	var fe *FieldErrors
+	require.ErrorAs(t, err, &fe)
+}
+
+func TestApplyJSONPatch_ErrorPath(t *testing.T) {
+	v := newPatchStruct()
+	_, err := clientDecoder.ApplyJSONPatch(v, []byte(`[{"op": "replace", "path": "/items/0", "value": {"qty": "x"}}]`), scopes.DECODE_UPDATE)
+	var e *Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, "items[0].qty", e.Path)
+
+	_, err = clientDecoder.ApplyJSONPatch(v, []byte(`[{"op": "replace", "path": "/address/zip", "value": "x"}]`), scopes.DECODE_UPDATE)
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, "address.zip", e.Path)
+
+	_, err = strictDecoder.ApplyJSONPatch(&StrictStruct{}, []byte(`[{"op":"replace","path":"/items","value":[{"unknown":1}]}]`), scopes.DECODE_UPDATE)
+	var fe *FieldErrors
+	require.ErrorAs(t, err, &fe)
+	require.Len(t, fe.Errors, 1)
+	require.Equal(t, "items[0].unknown", fe.Errors[0].Path)
+}
@@ -1,7 +1,6 @@
 package decoder
 
 import (
-	"fmt"
 	"reflect"
 	"strconv"
 )
@@ -100,92 +99,104 @@ func (d *Decoder) SkipMinus(isFloat bool) error {
 	}
 }
 
-func decodeInt(d *Decoder, v reflect.Value) error {
+func (d *Decoder) decodeToInt(bits int) (int64, error) {
 	d.SkipWhitespace()
 	c := d.char()
 	d.start = d.pos
 	switch c {
 	case '"':
+		start := d.pos
 		d.pos++
-		err := decodeInt(d, v)
+		n, err := d.decodeToInt(bits)
 		if err != nil {
-			return err
+			return 0, d.quotedError(err, start)
 		}
 		d.pos++
-		return nil
+		return n, nil
 	case 'n':
 		err := d.ScanNull()
-		if err != nil {
-			return err
-		}
-		v.SetInt(0)
-		return nil
+		return 0, err
 	case '-':
-		err := d.SkipMinus(false)
+		err := d.SkipMinus(true)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	case '0':
-		err := d.SkipZero(false)
+		err := d.SkipZero(true)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
-		err := d.SkipNumber(false, false)
+		err := d.SkipNumber(true, true)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	default:
-		return d.Error("[Blaze decodeInt()] invalid char, expected '-' or integer")
+		return 0, d.mismatchError("[Blaze decodeInt()] invalid char, expected '-' or integer")
 	}
 
+	// The number is valid, so fractions and overflows are type errors.
 	str := BytesToString(d.Buf[d.start:d.pos])
-	n, err := strconv.ParseInt(str, 10, v.Type().Bits())
+	n, err := strconv.ParseInt(str, 10, bits)
 	if err != nil {
-		return d.Error(err.Error())
+		return 0, d.TypeError(err.Error())
+	}
+	return n, nil
+}
+
+func decodeInt(d *Decoder, v reflect.Value) error {
+	n, err := d.decodeToInt(v.Type().Bits())
+	if err != nil {
+		return err
 	}
 	v.SetInt(n)
 	return nil
 }
 
-func decodeUint(d *Decoder, v reflect.Value) error {
+func (d *Decoder) decodeToUint(bits int) (uint64, error) {
 	d.SkipWhitespace()
 	c := d.char()
 	d.start = d.pos
 	switch c {
 	case '"':
+		start := d.pos
 		d.pos++
-		err := decodeUint(d, v)
+		n, err := d.decodeToUint(bits)
 		if err != nil {
-			return err
+			return 0, d.quotedError(err, start)
 		}
 		d.pos++
-		return nil
+		return n, nil
 	case 'n':
 		err := d.ScanNull()
-		if err != nil {
-			return err
-		}
-		v.SetUint(0)
-		return nil
+		return 0, err
 	case '0':
-		err := d.SkipZero(false)
+		err := d.SkipZero(true)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	case '1', '2', '3', '4', '5', '6', '7', '8', '9':
-		err := d.SkipNumber(false, false)
+		err := d.SkipNumber(true, true)
 		if err != nil {
-			return err
+			return 0, err
 		}
 	default:
-		return d.Error("[Blaze decodeInt()] invalid char, expected '-' or integer")
+		return 0, d.mismatchError("[Blaze decodeUint()] invalid char, expected unsigned integer")
 	}
 
+	// The number is valid, so fractions and overflows are type errors.
 	str := BytesToString(d.Buf[d.start:d.pos])
-	n, err := strconv.ParseUint(str, 10, v.Type().Bits())
+	n, err := strconv.ParseUint(str, 10, bits)
 	if err != nil {
-		return d.Error(err.Error())
+		return 0, d.TypeError(err.Error())
+	}
+	return n, nil
+}
+
+func decodeUint(d *Decoder, v reflect.Value) error {
+	n, err := d.decodeToUint(v.Type().Bits())
+	if err != nil {
+		return err
 	}
 	v.SetUint(n)
 	return nil
@@ -216,12 +227,16 @@ func (d *Decoder) decodeToFloat(bits int) (float64, error) {
 			return 0, err
 		}
 	default:
-		fmt.Println(string(d.Buf))
-		return 0, d.ErrorF("[Blaze decodeFloat()] invalid char %s, expected '-' or integer", string(c))
+		return 0, d.mismatchError("[Blaze decodeFloat()] invalid char " + string(c) + ", expected '-' or integer")
 	}
 
+	// The number is valid, so overflows are type errors.
 	str := BytesToString(d.Buf[d.start:d.pos])
-	return strconv.ParseFloat(str, bits)
+	fl, err := strconv.ParseFloat(str, bits)
+	if err != nil {
+		return 0, d.TypeError(err.Error())
+	}
+	return fl, nil
 }
 
 func decodeFloat(d *Decoder, v reflect.Value) error {
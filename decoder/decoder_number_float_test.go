@@ -6,6 +6,7 @@ import (
 
 	gojson "github.com/goccy/go-json"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDecode_Float32(t *testing.T) {
@@ -40,6 +41,28 @@ func TestDecode_Float32(t *testing.T) {
 	EqualUnmarshaling[float32](t, data)
 }
 
+func TestDecode_Float_Quoted(t *testing.T) {
+	// Only float map keys are quoted, see TestDecode_Map.
+	var f float64
+	require.Error(t, DDecoder.Unmarshal([]byte(`"1.5"`), &f))
+	var m map[float64]int
+	require.Error(t, DDecoder.Unmarshal([]byte(`{"1.5x":1}`), &m))
+}
+
+func TestDecode_Float_Range(t *testing.T) {
+	var f32 float32
+	err := DDecoder.Unmarshal([]byte(`1e40`), &f32)
+	require.ErrorIs(t, err, ErrType)
+	var te *TypeError
+	require.ErrorAs(t, err, &te)
+	require.Contains(t, te.Message, "value out of range")
+
+	var f64 float64
+	require.ErrorIs(t, DDecoder.Unmarshal([]byte(`[1e400]`), &[]float64{}), ErrType)
+	require.NoError(t, DDecoder.Unmarshal([]byte(`1e40`), &f64))
+	require.Equal(t, 1e40, f64)
+}
+
 func TestDecode_Float64(t *testing.T) {
 	data := []byte("100.123")
 	EqualUnmarshaling[float64](t, data)
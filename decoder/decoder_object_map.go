@@ -65,15 +65,20 @@ func (d *Decoder) decodeMap(v reflect.Value, keyDec, elemDec DecoderFn) error {
 		if err != nil {
 			return err
 		}
+		if d.tracking() && !v.IsNil() {
+			if err := d.removeMapChanges(v); err != nil {
+				return err
+			}
+		}
 		v.SetZero()
 		return nil
 	case '{':
 	default:
-		return d.Error("[Blaze decodeMap()] expected '{' or 'null'")
+		return d.mismatchError("[Blaze decodeMap()] expected '{' or 'null'")
 	}
 	d.depth++
 	if d.depth > MAX_DEPTH {
-		return d.Error("[Blaze decodeMap()] max depth reached")
+		return d.DepthError("[Blaze decodeMap()] max depth reached")
 	}
 	size, err := d.ScanObject()
 	if err != nil {
@@ -99,10 +104,12 @@ func (d *Decoder) decodeMap(v reflect.Value, keyDec, elemDec DecoderFn) error {
 		}
 
 		key := reflect.New(v.Type().Key()).Elem()
+		keyStart := d.pos
 		err := keyDec(d, key)
 		if err != nil {
 			return err
 		}
+		keyName := BytesToString(d.Buf[keyStart+1 : d.pos-1])
 		d.SkipWhitespace()
 
 		c = d.char()
@@ -111,11 +118,13 @@ func (d *Decoder) decodeMap(v reflect.Value, keyDec, elemDec DecoderFn) error {
 		}
 		d.pos++
 		d.SkipWhitespace()
-		value := reflect.New(v.Type().Elem()).Elem()
-		if err := elemDec(d, value); err != nil {
-			return err
+		n := len(d.fieldErrors)
+		if err := d.decodeMapValue(v, key, keyName, elemDec); err != nil {
+			return withKey(err, keyName)
+		}
+		if len(d.fieldErrors) > n {
+			d.keyFieldErrors(n, keyName)
 		}
-		v.SetMapIndex(key, value)
 		d.SkipWhitespace()
 		c = d.char()
 		switch c {
@@ -133,10 +142,72 @@ func (d *Decoder) decodeMap(v reflect.Value, keyDec, elemDec DecoderFn) error {
 	}
 }
 
+// decodeMapValue decodes the value of the key and stores it in the map.
+func (d *Decoder) decodeMapValue(v, key reflect.Value, keyName string, elemDec DecoderFn) error {
+	old := v.MapIndex(key)
+	if d.mergePatch && d.char() == 'n' {
+		// Merge patch removes keys set to null.
+		if err := d.ScanNull(); err != nil {
+			return err
+		}
+		if !old.IsValid() {
+			return nil
+		}
+		if d.tracking() {
+			if err := d.removeChange(d.keyNode(keyName), old); err != nil {
+				return err
+			}
+		}
+		v.SetMapIndex(key, reflect.Value{})
+		return nil
+	}
+	value := reflect.New(v.Type().Elem()).Elem()
+	if d.mergePatch && d.char() == '{' && old.IsValid() {
+		// Merge patch merges objects into the old value.
+		value.Set(old)
+	}
+	if d.tracking() {
+		// Map values are decoded into a new value, so they are tracked as a whole.
+		ch, err := d.beginChange(d.keyNode(keyName), old, false)
+		if err != nil {
+			return err
+		}
+		if err := d.decodeElem(elemDec, value); err != nil {
+			return err
+		}
+		if err := d.endChange(ch, value); err != nil {
+			return err
+		}
+	} else if err := d.decodeElem(elemDec, value); err != nil {
+		return err
+	}
+	v.SetMapIndex(key, value)
+	return nil
+}
+
 func newMapEncoder(t reflect.Type) DecoderFn {
 	keyDec := newDecoderFn(t.Key(), true)
+	if k := t.Key().Kind(); (k == reflect.Float32 || k == reflect.Float64) && !hasCustomDecoder(t.Key()) {
+		keyDec = decodeFloatKey
+	}
 	elemDec := newDecoderFn(t.Elem(), true)
 	return func(d *Decoder, v reflect.Value) error {
 		return d.decodeMap(v, keyDec, elemDec)
 	}
 }
+
+// decodeFloatKey decodes a float map key, which is a quoted number, e.g. "2.5". Other floats can't be quoted.
+func decodeFloatKey(d *Decoder, v reflect.Value) error {
+	if d.char() != '"' {
+		return decodeFloat(d, v)
+	}
+	d.pos++
+	if err := decodeFloat(d, v); err != nil {
+		return err
+	}
+	if d.char() != '"' {
+		return d.Error("[Blaze decodeMap()] invalid char, expected '\"'")
+	}
+	d.pos++
+	return nil
+}
@@ -1,20 +1,19 @@
 package decoder
 
 import (
-	"fmt"
 	"reflect"
 
+	"github.com/deveox/blaze/scopes"
 	"github.com/deveox/blaze/types"
 )
 
 func (d *Decoder) decodeStruct(v reflect.Value, si *types.Struct) error {
 	d.depth++
 	if d.depth > MAX_DEPTH {
-		return d.Error("[Blaze decodeStruct()] max depth reached")
+		return d.DepthError("[Blaze decodeStruct()] max depth reached")
 	}
 	d.SkipWhitespace()
 	c := d.char()
-	prefix := d.ChangesPrefix
 	switch c {
 	case '{':
 		d.pos++
@@ -24,27 +23,33 @@ func (d *Decoder) decodeStruct(v reflect.Value, si *types.Struct) error {
 			return err
 		}
 		for _, fi := range si.Fields {
-			ok := fi.Field.CheckDecoderScope(d.config.Scope, d.operation)
+			ok := fi.Field.CheckDecoderScope(d.config.Scope, d.operation) && d.config.Policies.Check(d.Ctx, si, v, fi.Field)
 			if ok {
 				f := fi.Value(v)
 				if f.IsZero() {
 					continue
 				}
-				if d.Changes != nil {
-					if prefix == "" {
-						d.Changes = append(d.Changes, fi.Field.Name)
-					} else {
-						d.Changes = append(d.Changes, fmt.Sprintf("%s.%s", prefix, fi.Field.Name))
-					}
+				if d.tracking() {
+					d.addChange(fieldNode(fi))
 				}
 				f.SetZero()
 			}
 		}
 		return nil
 	default:
-		return d.Error("[Blaze decodeStruct()] expected '{' or 'null'")
+		return d.mismatchError("[Blaze decodeStruct()] expected '{' or 'null'")
 	}
 
+	// Bitset of decoded fields by [types.StructField.Index], used to find missing required fields.
+	var seen []uint64
+	var seenBuf [4]uint64
+	if si.HasRequired && d.operation != scopes.DECODE_ANY {
+		if len(si.Fields) <= len(seenBuf)*64 {
+			seen = seenBuf[:]
+		} else {
+			seen = make([]uint64, (len(si.Fields)+63)/64)
+		}
+	}
 	for {
 		d.SkipWhitespace()
 		c := d.char()
@@ -52,6 +57,7 @@ func (d *Decoder) decodeStruct(v reflect.Value, si *types.Struct) error {
 		case '}':
 			d.pos++
 			d.depth--
+			d.checkRequired(v, si, seen)
 			return nil
 		case '"':
 		case TERMINATION_CHAR:
@@ -73,46 +79,16 @@ func (d *Decoder) decodeStruct(v reflect.Value, si *types.Struct) error {
 		d.pos++
 		d.SkipWhitespace()
 		field, ok := si.GetDecoderField(fName, d.config.Scope, d.operation)
-		// fmt.Printf("\nfield %v %s %#v\n\n", ok, v.Type(), field)
 		if ok {
-			fv := field.Value(v)
-			if d.Changes != nil {
-				if prefix == "" {
-					d.ChangesPrefix = field.Field.Name
-				} else {
-					d.ChangesPrefix = fmt.Sprintf("%s.%s", prefix, field.Field.Name)
-				}
-				d.Changes = append(d.Changes, d.ChangesPrefix)
-			}
-			oldLen := len(d.Changes)
-			if field.Field.StringDecoding && d.char() == '"' {
-				s, err := d.DecodeString()
-				if err != nil {
-					return err
-				}
-				nd := d.Decoder([]byte(s))
-				if err := nd.decode(fv); err != nil {
-					nd.Release()
-					return err
-				}
-				nd.Release()
-			} else {
-				if err := d.decode(fv); err != nil {
-					return err
-				}
-			}
-
-			if field.Field.Struct != nil {
-				if len(d.Changes) == oldLen && len(d.Changes) > 0 {
-					d.Changes = d.Changes[:len(d.Changes)-1]
-				}
-			}
-
-		} else {
-			err := d.Skip()
-			if err != nil {
-				return err
-			}
+			ok = d.config.Policies.Check(d.Ctx, si, v, field.Field)
+		}
+		// fmt.Printf("\nfield %v %s %#v\n\n", ok, v.Type(), field)
+		n := len(d.fieldErrors)
+		if err := d.decodeStructField(v, si, field, ok, seen); err != nil {
+			return withKey(err, fName)
+		}
+		if len(d.fieldErrors) > n {
+			d.keyFieldErrors(n, fName)
 		}
 		// fmt.Println(1, string(d.Buf[d.pos:]))
 		d.SkipWhitespace()
@@ -121,6 +97,7 @@ func (d *Decoder) decodeStruct(v reflect.Value, si *types.Struct) error {
 		case '}':
 			d.pos++
 			d.depth--
+			d.checkRequired(v, si, seen)
 			return nil
 		case ',':
 			d.pos++
@@ -132,6 +109,103 @@ func (d *Decoder) decodeStruct(v reflect.Value, si *types.Struct) error {
 	}
 }
 
+// decodeStructField decodes the value of the field, or skips it if the field can't be decoded (ok is false).
+// Paths of the errors are relative to the field.
+func (d *Decoder) decodeStructField(v reflect.Value, si *types.Struct, field *types.StructField, ok bool, seen []uint64) error {
+	if !ok {
+		if d.config.Strict {
+			if field == nil {
+				d.addFieldError(REASON_UNKNOWN, "unknown field")
+			} else {
+				msg := "field can't be decoded in '" + d.config.Scope.Name() + "' context with '" + d.operation.Name() + "' operation"
+				d.addFieldError(REASON_FORBIDDEN, msg)
+				d.fieldErrors[len(d.fieldErrors)-1].Err = &ScopeError{ErrorInfo: d.newError(msg), Context: d.config.Scope, Operation: d.operation}
+			}
+		}
+		return d.Skip()
+	}
+	if field.Field.RulesError != nil {
+		return d.UnsupportedTypeError(field.Field.RulesError.Error(), field.Field.Type)
+	}
+	if seen != nil {
+		seen[field.Index/64] |= 1 << (field.Index % 64)
+		if d.char() == 'n' && field.Field.IsRequired(d.config.Scope, d.operation) {
+			d.addFieldError(REASON_REQUIRED, "field is required and can't be null")
+		}
+	}
+	fv := field.Value(v)
+	validate := d.char() != 'n'
+	var ch change
+	if d.tracking() {
+		var err error
+		byParts := !field.Field.StringDecoding && d.trackedByParts(fv)
+		if ch, err = d.beginFieldChange(field, fv, byParts); err != nil {
+			return err
+		}
+	}
+	valueStart, depth := d.pos, d.depth
+	if field.Field.StringDecoding && d.char() == '"' {
+		s, err := d.DecodeString()
+		if err != nil {
+			return err
+		}
+		nd := d.Decoder([]byte(s))
+		err = nd.decode(fv)
+		d.fieldErrors = append(d.fieldErrors, nd.fieldErrors...)
+		nd.Release()
+		if err != nil {
+			if err := d.valueError(d.quotedError(err, valueStart), fv, valueStart, depth, field, v.Type()); err != nil {
+				return err
+			}
+			validate = false
+		}
+	} else {
+		if err := d.decode(fv); err != nil {
+			if err := d.valueError(err, fv, valueStart, depth, field, v.Type()); err != nil {
+				return err
+			}
+			validate = false
+		}
+	}
+	if d.tracking() {
+		if err := d.endChange(ch, fv); err != nil {
+			return err
+		}
+		// Nested struct without changed fields isn't a change itself.
+		if field.Field.Struct != nil && d.changes.Len() == int(ch.node)+1 {
+			d.changes.nodes = d.changes.nodes[:ch.node]
+		}
+	}
+	if validate {
+		for _, r := range field.Field.Rules {
+			if msg := r.Check(fv); msg != "" {
+				d.addFieldError(REASON_INVALID, msg)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRequired records errors for required fields, which are not in the seen bitset.
+func (d *Decoder) checkRequired(v reflect.Value, si *types.Struct, seen []uint64) {
+	if seen == nil {
+		return
+	}
+	for _, fi := range si.Fields {
+		if seen[fi.Index/64]&(1<<(fi.Index%64)) != 0 || !fi.Field.IsRequired(d.config.Scope, d.operation) {
+			continue
+		}
+		if !d.config.Policies.Check(d.Ctx, si, v, fi.Field) {
+			continue
+		}
+		d.fieldErrors = append(d.fieldErrors, &FieldError{
+			Path:    fi.Field.Name,
+			Reason:  REASON_REQUIRED,
+			Message: "field is required",
+		})
+	}
+}
+
 func newStructDecoder(t reflect.Type) DecoderFn {
 	si := types.Cache.Get(t)
 	return func(d *Decoder, v reflect.Value) error {
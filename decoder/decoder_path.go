@@ -0,0 +1,290 @@
+package decoder
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned when the requested path doesn't exist in the input.
+var ErrNotFound = errors.New("[Blaze Get()] path not found")
+
+// Get returns the raw bytes of the value at the given path.
+// The path is a dot-separated list of object keys and array indexes, e.g. "items.3.id". An empty path refers to the whole input.
+// Only the values along the path are parsed, everything else is skipped. The input is copied into the pooled buffer of the decoder,
+// which is reused by following calls, so it doesn't allocate once the buffer is large enough. The returned slice points into data.
+func (c *Config) Get(data []byte, path string) ([]byte, error) {
+	d := c.NewDecoder(data)
+	defer d.Release()
+	if err := d.seek(path); err != nil {
+		return nil, err
+	}
+	start := d.pos
+	if err := d.Skip(); err != nil {
+		return nil, err
+	}
+	return data[start:d.pos], nil
+}
+
+// GetString returns the string at the given path, see [Config.Get].
+func (c *Config) GetString(data []byte, path string) (string, error) {
+	d := c.NewDecoder(data)
+	defer d.Release()
+	if err := d.seek(path); err != nil {
+		return "", err
+	}
+	return d.decodeToString()
+}
+
+// GetInt64 returns the integer at the given path, see [Config.Get].
+func (c *Config) GetInt64(data []byte, path string) (int64, error) {
+	d := c.NewDecoder(data)
+	defer d.Release()
+	if err := d.seek(path); err != nil {
+		return 0, err
+	}
+	return d.decodeToInt(64)
+}
+
+// GetFloat64 returns the number at the given path, see [Config.Get].
+func (c *Config) GetFloat64(data []byte, path string) (float64, error) {
+	d := c.NewDecoder(data)
+	defer d.Release()
+	if err := d.seek(path); err != nil {
+		return 0, err
+	}
+	return d.decodeToFloat(64)
+}
+
+// GetBool returns the boolean at the given path, see [Config.Get].
+func (c *Config) GetBool(data []byte, path string) (bool, error) {
+	d := c.NewDecoder(data)
+	defer d.Release()
+	if err := d.seek(path); err != nil {
+		return false, err
+	}
+	return d.decodeToBool()
+}
+
+// GetValue decodes the value at the given path into v, see [Config.Get].
+func (c *Config) GetValue(data []byte, path string, v any) error {
+	d := c.NewDecoder(data)
+	defer d.Release()
+	d.Ctx.Clear()
+	if err := d.seek(path); err != nil {
+		return err
+	}
+	return d.unmarshal(v)
+}
+
+// Location describes where a path is located in the input, see [Config.Locate].
+type Location struct {
+	// Start and End are offsets of the value at the path.
+	// If the path doesn't exist, they are offsets of the deepest existing value on the path.
+	Start, End int
+	// Member is an offset of the object key or the array element that holds the value. It equals -1 for the root value.
+	Member int
+	// Rest is the part of the path which doesn't exist in the input. It's empty if the whole path exists.
+	Rest string
+	// Len is a number of elements in the array at Start, when the index from Rest is out of range.
+	Len int
+}
+
+// Found reports whether the whole path exists in the input.
+func (l *Location) Found() bool {
+	return l.Rest == ""
+}
+
+// Locate finds the value at the given path, see [Config.Get] for the path syntax.
+// If the path doesn't exist, the returned location points to the deepest existing value on the path and [Location.Rest] holds the rest of the path.
+func (c *Config) Locate(data []byte, path string) (Location, error) {
+	d := c.NewDecoder(data)
+	defer d.Release()
+	loc := Location{Member: -1}
+	for {
+		d.SkipWhitespace()
+		loc.Start = int(d.pos)
+		if path == "" {
+			break
+		}
+		part, rest, _ := strings.Cut(path, ".")
+		var err error
+		switch d.char() {
+		case '{':
+			err = d.seekKey(part)
+		case '[':
+			i, convErr := strconv.Atoi(part)
+			if convErr != nil || i < 0 {
+				err = ErrNotFound
+				break
+			}
+			err = d.seekIndex(i)
+		case TERMINATION_CHAR:
+			return loc, d.Error("[Blaze Locate()] unexpected end of input, expected beginning of value")
+		default:
+			err = ErrNotFound
+		}
+		if err == ErrNotFound {
+			loc.Rest = path
+			if d.Buf[loc.Start] == '[' {
+				loc.Len, err = d.countElements(loc.Start)
+				if err != nil {
+					return loc, err
+				}
+			}
+			d.pos = int64(loc.Start)
+			break
+		}
+		if err != nil {
+			return loc, err
+		}
+		loc.Member = int(d.start)
+		path = rest
+	}
+	if err := d.Skip(); err != nil {
+		return loc, err
+	}
+	loc.End = int(d.pos)
+	return loc, nil
+}
+
+// countElements returns the number of elements in the array at the given offset.
+func (d *Decoder) countElements(start int) (int, error) {
+	d.pos = int64(start) + 1
+	for n := 0; ; n++ {
+		d.SkipWhitespace()
+		if n == 0 && d.char() == ']' {
+			return 0, nil
+		}
+		if err := d.Skip(); err != nil {
+			return 0, err
+		}
+		d.SkipWhitespace()
+		switch d.char() {
+		case ',':
+			d.pos++
+		case ']':
+			return n + 1, nil
+		case TERMINATION_CHAR:
+			return 0, d.Error("[Blaze countElements()] unexpected end of input, expected ',' or ']'")
+		default:
+			return 0, d.Error("[Blaze countElements()] expected ',' or ']'")
+		}
+	}
+}
+
+// seek moves the decoder to the beginning of the value at the given path.
+func (d *Decoder) seek(path string) error {
+	for path != "" {
+		var part string
+		part, path, _ = strings.Cut(path, ".")
+		d.SkipWhitespace()
+		var err error
+		switch d.char() {
+		case '{':
+			err = d.seekKey(part)
+		case '[':
+			i, convErr := strconv.Atoi(part)
+			if convErr != nil || i < 0 {
+				return ErrNotFound
+			}
+			err = d.seekIndex(i)
+		case TERMINATION_CHAR:
+			return d.Error("[Blaze seek()] unexpected end of input, expected beginning of value")
+		default:
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	d.SkipWhitespace()
+	return nil
+}
+
+// seekKey moves the decoder from the beginning of an object to the value of the given key.
+func (d *Decoder) seekKey(key string) error {
+	d.pos++
+	for {
+		d.SkipWhitespace()
+		switch d.char() {
+		case '}':
+			return ErrNotFound
+		case '"':
+		case TERMINATION_CHAR:
+			return d.Error("[Blaze seekKey()] unexpected end of input, expected object key or '}'")
+		default:
+			return d.Error("[Blaze seekKey()] expected object key or '}'")
+		}
+		start := d.pos
+		if err := d.SkipString(); err != nil {
+			return err
+		}
+		raw := d.Buf[start+1 : d.pos-1]
+		found := BytesToString(raw) == key
+		if !found && bytes.IndexByte(raw, '\\') != -1 {
+			end := d.pos
+			d.pos = start
+			k, err := d.DecodeString()
+			if err != nil {
+				return err
+			}
+			found = k == key
+			d.pos = end
+		}
+		d.SkipWhitespace()
+		if d.char() != ':' {
+			return d.Error("[Blaze seekKey()] expected ':'")
+		}
+		d.pos++
+		d.SkipWhitespace()
+		if found {
+			d.start = start
+			return nil
+		}
+		if err := d.Skip(); err != nil {
+			return err
+		}
+		d.SkipWhitespace()
+		switch d.char() {
+		case ',':
+			d.pos++
+		case '}':
+			return ErrNotFound
+		case TERMINATION_CHAR:
+			return d.Error("[Blaze seekKey()] unexpected end of input, expected ',' or '}'")
+		default:
+			return d.Error("[Blaze seekKey()] expected ',' or '}'")
+		}
+	}
+}
+
+// seekIndex moves the decoder from the beginning of an array to the element with the given index.
+func (d *Decoder) seekIndex(idx int) error {
+	d.pos++
+	for i := 0; ; i++ {
+		d.SkipWhitespace()
+		if d.char() == ']' {
+			return ErrNotFound
+		}
+		if i == idx {
+			d.start = d.pos
+			return nil
+		}
+		if err := d.Skip(); err != nil {
+			return err
+		}
+		d.SkipWhitespace()
+		switch d.char() {
+		case ',':
+			d.pos++
+		case ']':
+			return ErrNotFound
+		case TERMINATION_CHAR:
+			return d.Error("[Blaze seekIndex()] unexpected end of input, expected ',' or ']'")
+		default:
+			return d.Error("[Blaze seekIndex()] expected ',' or ']'")
+		}
+	}
+}
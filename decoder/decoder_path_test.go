@@ -0,0 +1,89 @@
+package decoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var pathJSON = []byte(`{
+	"meta": {"version": 3, "name": "test", "ok": true},
+	"skip": {"a": [1, {"b": "}"}], "c": "]"},
+	"items": [
+		{"id": 1},
+		{"id": 2, "price": 1.5},
+		{"id": 3, "tags": ["x", "y"]}
+	],
+	"esc\"aped": "value",
+	"quoted": "a\nb"
+}`)
+
+func TestGet(t *testing.T) {
+	res, err := DDecoder.Get(pathJSON, "meta.version")
+	require.NoError(t, err)
+	require.Equal(t, "3", string(res))
+
+	res, err = DDecoder.Get(pathJSON, "items.2")
+	require.NoError(t, err)
+	require.Equal(t, `{"id": 3, "tags": ["x", "y"]}`, string(res))
+
+	res, err = DDecoder.Get(pathJSON, "items.2.tags.1")
+	require.NoError(t, err)
+	require.Equal(t, `"y"`, string(res))
+
+	res, err = DDecoder.Get(pathJSON, `esc"aped`)
+	require.NoError(t, err)
+	require.Equal(t, `"value"`, string(res))
+
+	res, err = DDecoder.Get([]byte(` [1, 2] `), "")
+	require.NoError(t, err)
+	require.Equal(t, `[1, 2]`, string(res))
+
+	for _, path := range []string{"missing", "meta.missing", "items.3", "items.x", "items.-1", "meta.version.x", "items.0.id.0"} {
+		_, err = DDecoder.Get(pathJSON, path)
+		require.ErrorIs(t, err, ErrNotFound, path)
+	}
+
+	_, err = DDecoder.Get([]byte(`{"a":{"b":1`), "a.c")
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrNotFound)
+
+	// The input is copied into the reused buffer of the decoder.
+	allocs := testing.AllocsPerRun(10, func() {
+		_, _ = DDecoder.Get(pathJSON, "items.2.tags.1")
+	})
+	require.Zero(t, allocs)
+}
+
+func TestGet_Typed(t *testing.T) {
+	s, err := DDecoder.GetString(pathJSON, "quoted")
+	require.NoError(t, err)
+	require.Equal(t, "a\nb", s)
+
+	i, err := DDecoder.GetInt64(pathJSON, "items.1.id")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), i)
+
+	f, err := DDecoder.GetFloat64(pathJSON, "items.1.price")
+	require.NoError(t, err)
+	require.Equal(t, 1.5, f)
+
+	b, err := DDecoder.GetBool(pathJSON, "meta.ok")
+	require.NoError(t, err)
+	require.True(t, b)
+
+	var tags []string
+	err = DDecoder.GetValue(pathJSON, "items.2.tags", &tags)
+	require.NoError(t, err)
+	require.Equal(t, []string{"x", "y"}, tags)
+
+	_, err = DDecoder.GetInt64(pathJSON, "meta.name")
+	require.Error(t, err)
+}
+
+func BenchmarkGet(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		DDecoder.Get(pathJSON, "items.2.tags.1")
+	}
+}
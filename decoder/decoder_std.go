@@ -22,5 +22,5 @@ func decodeStd(d *Decoder, v reflect.Value) error {
 		return err
 	}
 	u := v.Interface().(json.Unmarshaler)
-	return u.UnmarshalJSON(d.Buf[d.start:d.pos])
+	return d.unmarshalerError(u.UnmarshalJSON(d.Buf[d.start:d.pos]), d.start)
 }
@@ -0,0 +1,234 @@
+package decoder
+
+import (
+	"errors"
+	"io"
+
+	"github.com/deveox/blaze/ctx"
+	"github.com/deveox/blaze/scopes"
+)
+
+// STREAM_MIN_READ is the minimal amount of free space in the stream buffer before reading from the underlying reader.
+const STREAM_MIN_READ = 512
+
+// StreamDecoder reads and decodes consecutive JSON values from an input stream.
+// Only the value being decoded is kept in memory, so the buffer size is bounded by the largest top-level value, not by the whole stream.
+type StreamDecoder struct {
+	config *Config
+	r      io.Reader
+	err    error
+	buf    []byte
+	// Start of the unread data in buf.
+	scanp int
+	// Number of bytes dropped from the beginning of buf, used to report offsets in the whole stream.
+	offset int64
+
+	// State of the value scanner, so refilling the buffer doesn't require scanning the value from the beginning.
+	scanned  int
+	level    int
+	started  bool
+	scalar   bool
+	inString bool
+	escaped  bool
+}
+
+// NewStreamDecoder creates a new decoder that reads from r.
+// The decoder introduces its own buffering and may read data from r beyond the JSON values requested.
+func (c *Config) NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{config: c, r: r}
+}
+
+// Decode reads the next JSON value from the stream and decodes it into the given value.
+// It returns [io.EOF] when there are no more values in the stream.
+func (s *StreamDecoder) Decode(v any) error {
+	_, err := s.decode(v, scopes.DECODE_ANY, nil, false)
+	return err
+}
+
+// DecodeCtx works like [StreamDecoder.Decode], but sets the [*ctx.Ctx] of the decoder.
+func (s *StreamDecoder) DecodeCtx(v any, ctx *ctx.Ctx) error {
+	_, err := s.decode(v, scopes.DECODE_ANY, ctx, false)
+	return err
+}
+
+// DecodeScoped reads the next JSON value from the stream and decodes it into the given value with the given scope.
+func (s *StreamDecoder) DecodeScoped(v any, operation scopes.Decoding) error {
+	_, err := s.decode(v, operation, nil, false)
+	return err
+}
+
+// DecodeScopedCtx works like [StreamDecoder.DecodeScoped], but sets the [*ctx.Ctx] of the decoder.
+func (s *StreamDecoder) DecodeScopedCtx(v any, operation scopes.Decoding, ctx *ctx.Ctx) error {
+	_, err := s.decode(v, operation, ctx, false)
+	return err
+}
+
+// DecodeScopedWithChanges reads the next JSON value from the stream, decodes it into the given value with the given scope and returns the changes.
+// The changes are the paths of the fields that have been changed.
+func (s *StreamDecoder) DecodeScopedWithChanges(v any, operation scopes.Decoding) ([]string, error) {
+	return s.decode(v, operation, nil, true)
+}
+
+// More reports whether there is another value in the stream.
+func (s *StreamDecoder) More() bool {
+	for {
+		for ; s.scanp < len(s.buf); s.scanp++ {
+			switch s.buf[s.scanp] {
+			case ' ', '\t', '\n', '\r':
+			default:
+				return true
+			}
+		}
+		if s.err != nil {
+			return false
+		}
+		s.refill()
+	}
+}
+
+// InputOffset returns the offset of the current position in the stream.
+func (s *StreamDecoder) InputOffset() int64 {
+	return s.offset + int64(s.scanp)
+}
+
+func (s *StreamDecoder) decode(v any, operation scopes.Decoding, c *ctx.Ctx, withChanges bool) ([]string, error) {
+	end, err := s.readValue()
+	if err != nil {
+		return nil, err
+	}
+	start := s.scanp
+	s.scanp = end
+
+	t := s.config.NewDecoder(s.buf[start:end])
+	defer s.config.decoderPool.Put(t)
+	// Restore own context of the pooled decoder, so the caller's context is never cleared by other calls.
+	own := t.Ctx
+	defer func() {
+		t.Ctx = own
+	}()
+	if c != nil {
+		t.Ctx = c
+	} else {
+		t.Ctx.Clear()
+	}
+	t.operation = operation
+	if withChanges {
+		t.changeSet.Reset()
+		t.changes = &t.changeSet
+	}
+	err = t.unmarshal(v)
+	if err == nil {
+		t.SkipWhitespace()
+		if t.char() != TERMINATION_CHAR {
+			err = t.Error("[Blaze StreamDecoder.Decode()] invalid char after top-level value")
+		}
+	}
+	var changes []string
+	if withChanges {
+		changes = t.changeSet.Paths()
+		t.changes = nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		e.Offset += int(s.offset) + start
+	}
+	return changes, err
+}
+
+// readValue ensures that the buffer holds the next complete JSON value and returns the end of the value.
+// Leading whitespace is dropped, so s.scanp points to the beginning of the value.
+func (s *StreamDecoder) readValue() (int, error) {
+	s.scanned = s.scanp
+	s.level = 0
+	s.started = false
+	s.scalar = false
+	s.inString = false
+	s.escaped = false
+	for {
+		for ; s.scanned < len(s.buf); s.scanned++ {
+			c := s.buf[s.scanned]
+			if !s.started {
+				switch c {
+				case ' ', '\t', '\n', '\r':
+					s.scanp++
+					continue
+				case '{', '[':
+					s.level = 1
+				case '"':
+					s.inString = true
+				default:
+					s.scalar = true
+				}
+				s.started = true
+				continue
+			}
+			if s.inString {
+				switch {
+				case s.escaped:
+					s.escaped = false
+				case c == '\\':
+					s.escaped = true
+				case c == '"':
+					s.inString = false
+					if s.level == 0 {
+						return s.scanned + 1, nil
+					}
+				}
+				continue
+			}
+			if s.scalar {
+				switch c {
+				case ' ', '\t', '\n', '\r', ',', ']', '}', '{', '[', '"':
+					return s.scanned, nil
+				}
+				continue
+			}
+			switch c {
+			case '"':
+				s.inString = true
+			case '{', '[':
+				s.level++
+			case '}', ']':
+				s.level--
+				if s.level == 0 {
+					return s.scanned + 1, nil
+				}
+			}
+		}
+		if s.err != nil {
+			if s.err != io.EOF {
+				return 0, s.err
+			}
+			if !s.started {
+				return 0, io.EOF
+			}
+			// A number or a literal can be terminated only by the end of the stream.
+			if s.scalar {
+				return s.scanned, nil
+			}
+			return 0, io.ErrUnexpectedEOF
+		}
+		s.refill()
+	}
+}
+
+// refill drops already decoded data from the buffer and reads more data from the underlying reader.
+func (s *StreamDecoder) refill() {
+	if s.scanp > 0 {
+		s.offset += int64(s.scanp)
+		n := copy(s.buf, s.buf[s.scanp:])
+		s.buf = s.buf[:n]
+		s.scanned -= s.scanp
+		s.scanp = 0
+	}
+	if cap(s.buf)-len(s.buf) < STREAM_MIN_READ {
+		buf := make([]byte, len(s.buf), 2*cap(s.buf)+STREAM_MIN_READ)
+		copy(buf, s.buf)
+		s.buf = buf
+	}
+	n, err := s.r.Read(s.buf[len(s.buf):cap(s.buf)])
+	s.buf = s.buf[:len(s.buf)+n]
+	if err != nil {
+		s.err = err
+	}
+}
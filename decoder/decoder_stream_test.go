@@ -0,0 +1,107 @@
+package decoder
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/deveox/blaze/internal/testdata"
+	"github.com/deveox/blaze/scopes"
+	"github.com/stretchr/testify/require"
+)
+
+type StreamItem struct {
+	Name  string
+	Value int
+	Tags  []string
+}
+
+func TestStream_Decode(t *testing.T) {
+	data := `{"name":"first","value":1,"tags":["a","b"]}
+	{"name":"second \"quoted\" }","value":2}  {"name":"third","value":3}`
+	d := DDecoder.NewStreamDecoder(iotest.OneByteReader(strings.NewReader(data)))
+	var res []StreamItem
+	for d.More() {
+		var v StreamItem
+		err := d.Decode(&v)
+		require.NoError(t, err)
+		res = append(res, v)
+	}
+	require.Equal(t, []StreamItem{
+		{Name: "first", Value: 1, Tags: []string{"a", "b"}},
+		{Name: "second \"quoted\" }", Value: 2},
+		{Name: "third", Value: 3},
+	}, res)
+	var v StreamItem
+	require.Equal(t, io.EOF, d.Decode(&v))
+}
+
+func TestStream_Scalars(t *testing.T) {
+	d := DDecoder.NewStreamDecoder(strings.NewReader(`1 "two" true null 5`))
+	var i int
+	require.NoError(t, d.Decode(&i))
+	require.Equal(t, 1, i)
+	var s string
+	require.NoError(t, d.Decode(&s))
+	require.Equal(t, "two", s)
+	var b bool
+	require.NoError(t, d.Decode(&b))
+	require.True(t, b)
+	var p *int
+	require.NoError(t, d.Decode(&p))
+	require.Nil(t, p)
+	require.NoError(t, d.Decode(&i))
+	require.Equal(t, 5, i)
+	require.Equal(t, io.EOF, d.Decode(&i))
+}
+
+func TestStream_LargeValues(t *testing.T) {
+	var buf bytes.Buffer
+	long := strings.Repeat("x", 3*STREAM_MIN_READ)
+	for i := 0; i < 100; i++ {
+		buf.WriteString(`{"name":"` + long + `","value":42}`)
+	}
+	d := DDecoder.NewStreamDecoder(&buf)
+	n := 0
+	for d.More() {
+		var v StreamItem
+		require.NoError(t, d.Decode(&v))
+		require.Equal(t, long, v.Name)
+		require.Equal(t, 42, v.Value)
+		n++
+	}
+	require.Equal(t, 100, n)
+	// The buffer holds only a few values at once.
+	require.Less(t, cap(d.buf), 4*len(long))
+}
+
+func TestStream_Scoped(t *testing.T) {
+	data := `{"create":true,"update":true} {"create":true,"update":true}`
+	d := adminDecoder.NewStreamDecoder(strings.NewReader(data))
+	var v testdata.ScopedStruct
+	require.NoError(t, d.DecodeScoped(&v, scopes.DECODE_CREATE))
+	require.Equal(t, testdata.ScopedStruct{Create: true}, v)
+	v = testdata.ScopedStruct{}
+	changes, err := d.DecodeScopedWithChanges(&v, scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+	require.Equal(t, testdata.ScopedStruct{Update: true}, v)
+	require.Equal(t, []string{"update"}, changes)
+}
+
+func TestStream_Errors(t *testing.T) {
+	d := DDecoder.NewStreamDecoder(strings.NewReader(`{"name":"ok"} {"value":"x"} {"name":`))
+	var v StreamItem
+	require.NoError(t, d.Decode(&v))
+	err := d.Decode(&v)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, 24, e.Offset)
+	require.Equal(t, io.ErrUnexpectedEOF, d.Decode(&v))
+
+	d = DDecoder.NewStreamDecoder(strings.NewReader(`12x`))
+	var i int
+	require.Error(t, d.Decode(&i))
+}
@@ -147,7 +147,7 @@ func (d *Decoder) decodeToString() (string, error) {
 		}
 		return "", nil
 	default:
-		return "", d.Error("[Blaze decodeString()] invalid char, expected '\"'")
+		return "", d.mismatchError("[Blaze decodeString()] invalid char, expected '\"'")
 	}
 	return d.DecodeString()
 }
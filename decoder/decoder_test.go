@@ -2,9 +2,12 @@ package decoder
 
 import (
 	"encoding/json"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/deveox/blaze/scopes"
 	"github.com/stretchr/testify/require"
 )
 
@@ -72,3 +75,276 @@ func TestUnmarshal_WithChanges(t *testing.T) {
 	require.Equal(t, []string{"name", "nested", "nested.name", "nested.age"}, changes)
 
 }
+
+func TestUnmarshal_WithChangeValues(t *testing.T) {
+	v := WithChanges{Name: "old", Age: 5, Nested: &WithChangesNested{Name: "nested"}}
+	data := []byte(`{"name":"new","age":10,"nested":{"name":"nested","nested":{"name":"deep"}}}`)
+	changes, err := DDecoder.UnmarshalWithChangeValues(data, &v)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Path: "name", Old: json.RawMessage(`"old"`), New: json.RawMessage(`"new"`)},
+		{Path: "nested.nested", Old: json.RawMessage(`null`), New: json.RawMessage(`{"name":"deep"}`)},
+	}, changes)
+
+	// Identical values are not reported
+	changes, err = DDecoder.UnmarshalWithChangeValues(data, &v)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	changes, err = DDecoder.UnmarshalWithChangeValues([]byte(`{"nested":null}`), &v)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Path: "nested", Old: json.RawMessage(`{"name":"nested","nested":{"name":"deep"}}`), New: json.RawMessage(`null`)},
+	}, changes)
+}
+
+type WithSecretChanges struct {
+	Name     string
+	Password string       `blaze:"write"`
+	Secret   *WithChanges `blaze:"write"`
+}
+
+func TestUnmarshal_WithChangeValues_WriteOnly(t *testing.T) {
+	v := WithSecretChanges{Name: "old", Password: "old"}
+	data := []byte(`{"name":"new","password":"new","secret":{"name":"new"}}`)
+	changes, err := DDecoder.UnmarshalWithChangeValues(data, &v)
+	require.NoError(t, err)
+	require.Equal(t, []Change{{Path: "name", Old: json.RawMessage(`"old"`), New: json.RawMessage(`"new"`)}}, changes)
+	require.Equal(t, "new", v.Password)
+
+	paths, err := DDecoder.UnmarshalWithChanges([]byte(`{"password":"newer","secret":{"name":"newer"}}`), &v)
+	require.NoError(t, err)
+	require.Equal(t, []string{"password", "secret", "secret.name"}, paths)
+}
+
+type CollectionChangesItem struct {
+	Qty  int
+	Name string
+}
+
+type CollectionChanges struct {
+	Tags     []string
+	Settings map[string]string
+	Items    []CollectionChangesItem
+}
+
+func TestUnmarshal_WithChanges_Collections(t *testing.T) {
+	v := CollectionChanges{
+		Tags:     []string{"a", "b", "c", "d"},
+		Settings: map[string]string{"theme": "light", "lang": "en"},
+		Items:    []CollectionChangesItem{{Qty: 1, Name: "x"}, {Qty: 2, Name: "y"}, {Qty: 3, Name: "z"}},
+	}
+	data := []byte(`{"tags":["a","b"],"settings":{"theme":"dark"},"items":[{"qty":1},{"qty":2},{"qty":5}]}`)
+	changes, err := DDecoder.UnmarshalWithChanges(data, &v)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"tags", "tags.2", "tags.3", "tags.0", "tags.1",
+		"settings", "settings.theme",
+		"items", "items.0", "items.0.qty", "items.1", "items.1.qty", "items.2", "items.2.qty",
+	}, changes)
+	require.Equal(t, []string{"a", "b"}, v.Tags)
+
+	changes, err = DDecoder.UnmarshalWithChanges([]byte(`{"settings":null,"tags":[]}`), &v)
+	require.NoError(t, err)
+	require.Equal(t, []string{"settings", "settings.lang", "settings.theme", "tags", "tags.0", "tags.1"}, changes)
+	require.Nil(t, v.Settings)
+	require.Empty(t, v.Tags)
+}
+
+func TestUnmarshal_WithChangeValues_Collections(t *testing.T) {
+	v := CollectionChanges{
+		Tags:     []string{"a", "b", "c"},
+		Settings: map[string]string{"theme": "light"},
+		Items:    []CollectionChangesItem{{Qty: 1, Name: "x"}, {Qty: 2, Name: "y"}},
+	}
+	data := []byte(`{"tags":["a","x"],"settings":{"theme":"light","lang":"en"},"items":[{"qty":1},{"qty":5},{"name":"z"}]}`)
+	changes, err := DDecoder.UnmarshalWithChangeValues(data, &v)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{Path: "tags.2", Old: json.RawMessage(`"c"`)},
+		{Path: "tags.1", Old: json.RawMessage(`"b"`), New: json.RawMessage(`"x"`)},
+		{Path: "settings.lang", New: json.RawMessage(`"en"`)},
+		{Path: "items.1.qty", Old: json.RawMessage(`2`), New: json.RawMessage(`5`)},
+		{Path: "items.2", New: json.RawMessage(`{"name":"z"}`)},
+	}, changes)
+}
+
+func TestUnmarshal_WithChangeSet(t *testing.T) {
+	v := CollectionChanges{Settings: map[string]string{"theme": "light"}}
+	data := []byte(`{"tags":["a"],"settings":{"theme":"dark"},"items":[{"qty":1}]}`)
+	var cs ChangeSet
+	err := DDecoder.UnmarshalWithChangeSet(data, &v, &cs)
+	require.NoError(t, err)
+	require.Equal(t, 7, cs.Len())
+	require.Equal(t, []string{"tags", "tags.0", "settings", "settings.theme", "items", "items.0", "items.0.qty"}, cs.Paths())
+	require.True(t, cs.Has("items.0.qty"))
+	require.True(t, cs.Has("settings"))
+	require.False(t, cs.Has("items.1.qty"))
+	require.False(t, cs.Has("qty"))
+	require.False(t, cs.Has("tags.0.x"))
+	require.Equal(t, "Tags", cs.Field(0).Field.TitleCase)
+	require.Nil(t, cs.Field(1))
+
+	var paths []string
+	cs.Each(func(path []byte) bool {
+		paths = append(paths, string(path))
+		return len(paths) < 2
+	})
+	require.Equal(t, []string{"tags", "tags.0"}, paths)
+
+	// The change set is reset and reused
+	err = DDecoder.UnmarshalWithChangeSet([]byte(`{"tags":["a"]}`), &v, &cs)
+	require.NoError(t, err)
+	require.Equal(t, []string{"tags", "tags.0"}, cs.Paths())
+	allocs := testing.AllocsPerRun(10, func() {
+		_ = DDecoder.UnmarshalWithChangeSet([]byte(`{"items":[{"qty":1}]}`), &v, &cs)
+	})
+	require.Zero(t, allocs)
+
+	// Map keys are copied into the change set, so tracking them doesn't allocate more than decoding.
+	data = []byte(`{"settings":{"theme":"dark","lang":"en"}}`)
+	allocs = testing.AllocsPerRun(10, func() {
+		_ = DDecoder.UnmarshalWithChangeSet(data, &v, &cs)
+	})
+	require.Equal(t, []string{"settings", "settings.theme", "settings.lang"}, cs.Paths())
+	require.Equal(t, testing.AllocsPerRun(10, func() {
+		_ = DDecoder.Unmarshal(data, &v)
+	}), allocs)
+}
+
+type ErrorAddress struct {
+	Zip int
+}
+
+type ErrorItem struct {
+	Address ErrorAddress
+}
+
+type ErrorStruct struct {
+	Name  string
+	Items []ErrorItem
+	Meta  map[string][]int
+}
+
+func TestError_Location(t *testing.T) {
+	data := []byte("{\n  \"name\": \"test\",\n  \"items\": [{\"ü\":1}, {\"address\": {\"zip\": \"x\"}}]\n}")
+	var v ErrorStruct
+	err := DDecoder.Unmarshal(data, &v)
+	var e *Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, "items[1].address.zip", e.Path)
+	require.Equal(t, 3, e.Line)
+	require.Equal(t, 43, e.Column)
+	require.Equal(t, reflect.TypeFor[int](), e.Type)
+	require.Equal(t, "ErrorAddress.Zip", e.Field)
+	require.Contains(t, e.Error(), "at line 3, column 43 (position 63, path items[1].address.zip, type int, field ErrorAddress.Zip)")
+
+	err = DDecoder.Unmarshal([]byte(`{"meta":{"a":[1,true]}}`), &v)
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, "meta.a[1]", e.Path)
+	require.Equal(t, 1, e.Line)
+	require.Equal(t, reflect.TypeFor[int](), e.Type)
+	require.Equal(t, "ErrorStruct.Meta", e.Field)
+
+	err = DDecoder.Unmarshal([]byte(`{"name" 1}`), &v)
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, "", e.Path)
+	require.Equal(t, reflect.TypeFor[ErrorStruct](), e.Type)
+	require.Equal(t, "", e.Field)
+}
+
+type failingUnmarshaler struct{}
+
+var errFailing = errors.New("failing")
+
+func (failingUnmarshaler) UnmarshalJSON([]byte) error { return errFailing }
+
+type TypedErrorStruct struct {
+	Age    int
+	Custom *failingUnmarshaler
+	Ch     chan int
+}
+
+type nestedStruct struct {
+	N *nestedStruct
+}
+
+func TestError_Kinds(t *testing.T) {
+	var v TypedErrorStruct
+	err := DDecoder.Unmarshal([]byte(`{"age":}`), &v)
+	require.ErrorIs(t, err, ErrSyntax)
+	var se *SyntaxError
+	require.ErrorAs(t, err, &se)
+	var e *Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, "age", e.Path)
+
+	err = DDecoder.Unmarshal([]byte(`{"name" 1}`), &v)
+	require.ErrorIs(t, err, ErrSyntax)
+
+	err = DDecoder.Unmarshal([]byte(`{"age":"x"}`), &v)
+	require.ErrorIs(t, err, ErrType)
+	require.NotErrorIs(t, err, ErrSyntax)
+	var te *TypeError
+	require.ErrorAs(t, err, &te)
+	require.Equal(t, "age", te.Path)
+	require.Equal(t, reflect.TypeFor[int](), te.Type)
+
+	for _, data := range []string{`{"age":1.5}`, `{"age":1e100}`, `{"age":{"a":[true]}}`} {
+		err = DDecoder.Unmarshal([]byte(data), &v)
+		require.ErrorIs(t, err, ErrType, data)
+		require.NotErrorIs(t, err, ErrSyntax, data)
+	}
+	for _, data := range []string{`{"age":1.}`, `{"age":{"a":[true}}`, `{"age":"1`, `{"age":x}`} {
+		err = DDecoder.Unmarshal([]byte(data), &v)
+		require.ErrorIs(t, err, ErrSyntax, data)
+		require.NotErrorIs(t, err, ErrType, data)
+	}
+
+	err = DDecoder.Unmarshal([]byte(`{"custom":1}`), &v)
+	require.ErrorIs(t, err, ErrUnmarshaler)
+	require.ErrorIs(t, err, errFailing)
+	var ue *UnmarshalerError
+	require.ErrorAs(t, err, &ue)
+	require.Equal(t, "custom", ue.Path)
+
+	err = DDecoder.Unmarshal([]byte(`{"ch":1}`), &v)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+	err = DDecoder.Unmarshal([]byte(`1`), v)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	var n nestedStruct
+	err = DDecoder.Unmarshal([]byte(strings.Repeat(`{"n":`, MAX_DEPTH+2)+"null"+strings.Repeat("}", MAX_DEPTH+2)), &n)
+	require.ErrorIs(t, err, ErrDepth)
+
+	err = strictDecoder.Unmarshal([]byte(`{"role":"admin"}`), &StrictStruct{})
+	require.ErrorIs(t, err, ErrScope)
+	var sce *ScopeError
+	require.ErrorAs(t, err, &sce)
+	require.Equal(t, scopes.CONTEXT_CLIENT, sce.Context)
+}
+
+func TestError_Location_Collected(t *testing.T) {
+	data := []byte("{\n\"name\": 1,\n\"age\": \"ü\", \"tags\": [1]\n}")
+	var s CollectStruct
+	err := collectDecoder.Unmarshal(data, &s)
+	var fe *FieldErrors
+	require.ErrorAs(t, err, &fe)
+	type location struct {
+		Line, Column int
+	}
+	res := []location{}
+	for _, f := range fe.Errors {
+		var e *Error
+		require.ErrorAs(t, f.Err, &e)
+		res = append(res, location{e.Line, e.Column})
+	}
+	require.Equal(t, []location{{2, 9}, {3, 9}, {3, 22}}, res)
+
+	// The position is computed from the beginning for the next input.
+	err = DDecoder.Unmarshal([]byte(`{"name" 1}`), &s)
+	var e *Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, 1, e.Line)
+	require.Equal(t, 9, e.Column)
+}
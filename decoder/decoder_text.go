@@ -23,5 +23,5 @@ func decodeText(d *Decoder, v reflect.Value) error {
 		return err
 	}
 	u := v.Interface().(encoding.TextUnmarshaler)
-	return u.UnmarshalText(d.Buf[d.start:d.pos])
+	return d.unmarshalerError(u.UnmarshalText(d.Buf[d.start:d.pos]), d.start)
 }
@@ -0,0 +1,248 @@
+package decoder
+
+import "reflect"
+
+// Kind is a kind of the next JSON value in the input, see [Decoder.PeekKind].
+type Kind int
+
+const (
+	KIND_INVALID Kind = iota
+	KIND_NULL
+	KIND_BOOL
+	KIND_NUMBER
+	KIND_STRING
+	KIND_OBJECT
+	KIND_ARRAY
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KIND_NULL:
+		return "null"
+	case KIND_BOOL:
+		return "boolean"
+	case KIND_NUMBER:
+		return "number"
+	case KIND_STRING:
+		return "string"
+	case KIND_OBJECT:
+		return "object"
+	case KIND_ARRAY:
+		return "array"
+	default:
+		return "invalid"
+	}
+}
+
+// PeekKind returns the kind of the next value without consuming it.
+// It returns [KIND_INVALID] at the end of input or if the next char can't start a value.
+func (d *Decoder) PeekKind() Kind {
+	d.SkipWhitespace()
+	switch d.char() {
+	case 'n':
+		return KIND_NULL
+	case 't', 'f':
+		return KIND_BOOL
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return KIND_NUMBER
+	case '"':
+		return KIND_STRING
+	case '{':
+		return KIND_OBJECT
+	case '[':
+		return KIND_ARRAY
+	default:
+		return KIND_INVALID
+	}
+}
+
+// EnterObject consumes the opening '{' of an object. Use [Decoder.More] to iterate over its keys.
+func (d *Decoder) EnterObject() error {
+	if err := d.checkValueStart(); err != nil {
+		return err
+	}
+	if d.char() != '{' {
+		return d.Error("[Blaze EnterObject()] expected '{'")
+	}
+	return d.enter()
+}
+
+// EnterArray consumes the opening '[' of an array. Use [Decoder.More] to iterate over its elements.
+func (d *Decoder) EnterArray() error {
+	if err := d.checkValueStart(); err != nil {
+		return err
+	}
+	if d.char() != '[' {
+		return d.Error("[Blaze EnterArray()] expected '['")
+	}
+	return d.enter()
+}
+
+func (d *Decoder) enter() error {
+	d.depth++
+	if d.depth > MAX_DEPTH {
+		return d.DepthError("[Blaze enter()] maximum depth reached")
+	}
+	d.pos++
+	return nil
+}
+
+// More reports whether the current object or array has another key or element.
+// It consumes the ',' separator before the next element, or the closing '}' or ']' when there are no more elements.
+// If the input is malformed, More returns true and the following read reports an error.
+//
+//	if err := d.EnterObject(); err != nil {
+//		return err
+//	}
+//	for d.More() {
+//		key, err := d.ReadObjectKey()
+//		...
+//	}
+func (d *Decoder) More() bool {
+	d.SkipWhitespace()
+	switch d.char() {
+	case ',':
+		if d.prevChar() == '{' || d.prevChar() == '[' {
+			return true
+		}
+		d.pos++
+		return true
+	case '}', ']':
+		if d.prevChar() == ',' {
+			return true
+		}
+		d.pos++
+		d.depth--
+		return false
+	default:
+		return true
+	}
+}
+
+// ReadObjectKey reads an object key and the following ':'.
+func (d *Decoder) ReadObjectKey() (string, error) {
+	if err := d.checkValueStart(); err != nil {
+		return "", err
+	}
+	if d.char() != '"' {
+		return "", d.Error("[Blaze ReadObjectKey()] expected object key")
+	}
+	if p := d.prevChar(); p != '{' && p != ',' {
+		return "", d.Error("[Blaze ReadObjectKey()] unexpected object key")
+	}
+	key, err := d.DecodeString()
+	if err != nil {
+		return "", err
+	}
+	d.SkipWhitespace()
+	if d.char() != ':' {
+		return "", d.Error("[Blaze ReadObjectKey()] expected ':'")
+	}
+	d.pos++
+	return key, nil
+}
+
+// ReadNull consumes the next value if it's 'null' and reports whether it was consumed.
+func (d *Decoder) ReadNull() bool {
+	d.SkipWhitespace()
+	if d.char() != 'n' {
+		return false
+	}
+	return d.ScanNull() == nil
+}
+
+// ReadString reads a string value. 'null' is read as an empty string.
+func (d *Decoder) ReadString() (string, error) {
+	if err := d.checkValueStart(); err != nil {
+		return "", err
+	}
+	return d.decodeToString()
+}
+
+// ReadInt64 reads an integer value, which can also be wrapped in a string. 'null' is read as 0.
+func (d *Decoder) ReadInt64() (int64, error) {
+	if err := d.checkValueStart(); err != nil {
+		return 0, err
+	}
+	return d.decodeToInt(64)
+}
+
+// ReadUint64 reads an unsigned integer value, which can also be wrapped in a string. 'null' is read as 0.
+func (d *Decoder) ReadUint64() (uint64, error) {
+	if err := d.checkValueStart(); err != nil {
+		return 0, err
+	}
+	return d.decodeToUint(64)
+}
+
+// ReadFloat64 reads a number value. Unlike integers, quoted numbers are not accepted. 'null' is read as 0.
+func (d *Decoder) ReadFloat64() (float64, error) {
+	if err := d.checkValueStart(); err != nil {
+		return 0, err
+	}
+	return d.decodeToFloat(64)
+}
+
+// ReadBool reads a boolean value. 'null' is read as false.
+func (d *Decoder) ReadBool() (bool, error) {
+	if err := d.checkValueStart(); err != nil {
+		return false, err
+	}
+	return d.decodeToBool()
+}
+
+// ReadValue decodes the next value into v, which must be a pointer. The scope and the operation of the decoder are respected.
+func (d *Decoder) ReadValue(v any) error {
+	if err := d.checkValueStart(); err != nil {
+		return err
+	}
+	return d.decodeValue(v)
+}
+
+// checkValueStart skips whitespace and checks that a value can start at the current position,
+// i.e. it's the beginning of the input or it follows '{', '[', ',' or ':'.
+func (d *Decoder) checkValueStart() error {
+	d.SkipWhitespace()
+	switch d.prevChar() {
+	case '{', '[', ',', ':', TERMINATION_CHAR:
+		return nil
+	}
+	switch d.char() {
+	case TERMINATION_CHAR:
+		return d.Error("[Blaze checkValueStart()] unexpected end of input")
+	case '}', ']':
+		return d.Error("[Blaze checkValueStart()] unexpected end of object or array")
+	default:
+		return d.Error("[Blaze checkValueStart()] expected ',' before value")
+	}
+}
+
+// prevChar returns the last non-whitespace char before the current position or [TERMINATION_CHAR] at the beginning of input.
+func (d *Decoder) prevChar() byte {
+	for i := d.pos - 1; i >= 0; i-- {
+		switch d.Buf[i] {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return d.Buf[i]
+		}
+	}
+	return TERMINATION_CHAR
+}
+
+func decodeAddressableToken(d *Decoder, v reflect.Value) error {
+	return decodeToken(d, v.Addr())
+}
+
+func decodePtrToken(d *Decoder, v reflect.Value) error {
+	if v.IsNil() {
+		v.Set(reflect.New(v.Type().Elem()))
+	}
+	return decodeToken(d, v)
+}
+
+func decodeToken(d *Decoder, v reflect.Value) error {
+	d.SkipWhitespace()
+	start := d.pos
+	u := v.Interface().(TokenUnmarshaler)
+	return d.unmarshalerError(u.DecodeBlaze(d), start)
+}
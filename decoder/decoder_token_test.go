@@ -0,0 +1,129 @@
+package decoder
+
+import (
+	"testing"
+
+	"github.com/deveox/blaze/scopes"
+	"github.com/stretchr/testify/require"
+)
+
+type TokenPoint struct {
+	X      int64
+	Y      float64
+	Label  string
+	Tags   []string
+	Hidden bool
+	Nested *TokenNested
+}
+
+type TokenNested struct {
+	Name string `blaze:"read"`
+	Age  int
+}
+
+func (p *TokenPoint) DecodeBlaze(d *Decoder) error {
+	if d.ReadNull() {
+		*p = TokenPoint{}
+		return nil
+	}
+	if err := d.EnterObject(); err != nil {
+		return err
+	}
+	for d.More() {
+		key, err := d.ReadObjectKey()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "x":
+			p.X, err = d.ReadInt64()
+		case "y":
+			p.Y, err = d.ReadFloat64()
+		case "label":
+			p.Label, err = d.ReadString()
+		case "hidden":
+			if d.Context() != scopes.CONTEXT_ADMIN {
+				err = d.Skip()
+				break
+			}
+			p.Hidden, err = d.ReadBool()
+		case "tags":
+			if err = d.EnterArray(); err != nil {
+				return err
+			}
+			for d.More() {
+				var s string
+				if s, err = d.ReadString(); err != nil {
+					return err
+				}
+				p.Tags = append(p.Tags, s)
+			}
+		case "nested":
+			err = d.ReadValue(&p.Nested)
+		default:
+			err = d.Skip()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type TokenContainer struct {
+	Point  TokenPoint
+	Points []*TokenPoint
+}
+
+func TestToken_Unmarshaler(t *testing.T) {
+	data := []byte(`{"point":{"x":1, "y": 2.5, "label":"a\"b", "tags":["a", "b"], "hidden":true, "unknown":{"a":[1,2]}, "nested":{"name":"n","age":3}}, "points":[null, {"x":"2"}, {}]}`)
+	var v TokenContainer
+	err := DDecoder.Unmarshal(data, &v)
+	require.NoError(t, err)
+	require.Equal(t, TokenContainer{
+		Point: TokenPoint{X: 1, Y: 2.5, Label: `a"b`, Tags: []string{"a", "b"}, Hidden: true, Nested: &TokenNested{Age: 3}},
+		Points: []*TokenPoint{
+			{},
+			{X: 2},
+			{},
+		},
+	}, v)
+
+	v = TokenContainer{}
+	err = clientDecoder.Unmarshal(data, &v)
+	require.NoError(t, err)
+	require.False(t, v.Point.Hidden)
+}
+
+func TestToken_Malformed(t *testing.T) {
+	inputs := []string{
+		`{"x":1 "y":2}`,
+		`{"tags":["a" "b"]}`,
+		`{"x":1,}`,
+		`{,"x":1}`,
+		`{"x":1`,
+		`{"tags":["a",]}`,
+		`{"x" 1}`,
+		// floats can't be quoted
+		`{"y":"2.5"}`,
+	}
+	for _, in := range inputs {
+		var v TokenPoint
+		err := DDecoder.Unmarshal([]byte(in), &v)
+		require.Error(t, err, in)
+	}
+}
+
+func TestToken_PeekKind(t *testing.T) {
+	d := DDecoder.NewDecoder([]byte(` [null, true, -1, "s", {}, []]`))
+	defer d.Release()
+	require.Equal(t, KIND_ARRAY, d.PeekKind())
+	require.NoError(t, d.EnterArray())
+	kinds := []Kind{}
+	for d.More() {
+		kinds = append(kinds, d.PeekKind())
+		require.NoError(t, d.Skip())
+	}
+	require.Equal(t, []Kind{KIND_NULL, KIND_BOOL, KIND_NUMBER, KIND_STRING, KIND_OBJECT, KIND_ARRAY}, kinds)
+	require.Equal(t, KIND_INVALID, d.PeekKind())
+}
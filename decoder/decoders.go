@@ -49,10 +49,17 @@ type Unmarshaler interface {
 	UnmarshalBlaze(e *Decoder, data []byte) error
 }
 
+// TokenUnmarshaler is implemented by types that decode themselves directly from the input using the token API of [Decoder] (e.g. [Decoder.EnterObject], [Decoder.More], [Decoder.ReadObjectKey]).
+// Unlike [Unmarshaler], the value is parsed in a single pass. DecodeBlaze must consume exactly one value.
+type TokenUnmarshaler interface {
+	DecodeBlaze(d *Decoder) error
+}
+
 var (
-	stdUnmarshaler  = reflect.TypeFor[json.Unmarshaler]()
-	textUnmarshaler = reflect.TypeFor[encoding.TextMarshaler]()
-	unmarshaler     = reflect.TypeFor[Unmarshaler]()
+	stdUnmarshaler   = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshaler  = reflect.TypeFor[encoding.TextMarshaler]()
+	unmarshaler      = reflect.TypeFor[Unmarshaler]()
+	tokenUnmarshaler = reflect.TypeFor[TokenUnmarshaler]()
 )
 
 func newDecoderFn(t reflect.Type, allowAddr bool) DecoderFn {
@@ -62,6 +69,9 @@ func newDecoderFn(t reflect.Type, allowAddr bool) DecoderFn {
 	// allocation as we cast the value to an interface.
 	if t.Kind() != reflect.Pointer && allowAddr {
 		ptr := reflect.PointerTo(t)
+		if ptr.Implements(tokenUnmarshaler) {
+			return newIfAddressable(decodeAddressableToken, newDecoderFn(t, false))
+		}
 		if ptr.Implements(unmarshaler) {
 			return newIfAddressable(decodeAddressableCustom, newDecoderFn(t, false))
 		}
@@ -74,6 +84,10 @@ func newDecoderFn(t reflect.Type, allowAddr bool) DecoderFn {
 
 	}
 
+	if t.Implements(tokenUnmarshaler) {
+		return decodePtrToken
+	}
+
 	if t.Implements(unmarshaler) {
 		return decodePtrCustom
 	}
@@ -0,0 +1,269 @@
+package decoder
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+
+	"github.com/deveox/blaze/scopes"
+	"github.com/deveox/blaze/types"
+)
+
+// Sentinel errors to check the kind of a decoding error with [errors.Is], e.g. errors.Is(err, decoder.ErrSyntax).
+var (
+	ErrSyntax          = errors.New("[Blaze] syntax error")
+	ErrType            = errors.New("[Blaze] type error")
+	ErrUnsupportedType = errors.New("[Blaze] unsupported type")
+	ErrDepth           = errors.New("[Blaze] maximum depth reached")
+	ErrScope           = errors.New("[Blaze] scope violation")
+	ErrUnmarshaler     = errors.New("[Blaze] unmarshaler error")
+)
+
+// ErrorInfo is an alias of [Error], which is embedded by typed errors.
+// All of them can be also matched as [*Error] with [errors.As] to get the location of the error.
+type ErrorInfo = Error
+
+// SyntaxError is returned when the input isn't a valid JSON.
+type SyntaxError struct {
+	*ErrorInfo
+}
+
+func (e *SyntaxError) Unwrap() error        { return e.ErrorInfo }
+func (e *SyntaxError) Is(target error) bool { return target == ErrSyntax }
+
+// TypeError is returned when a valid JSON value can't be decoded into the Go type, e.g. a string into an int field.
+type TypeError struct {
+	*ErrorInfo
+}
+
+func (e *TypeError) Unwrap() error        { return e.ErrorInfo }
+func (e *TypeError) Is(target error) bool { return target == ErrType }
+
+// UnsupportedTypeError is returned when the Go type can't be decoded at all, e.g. a channel or a non-pointer value.
+type UnsupportedTypeError struct {
+	*ErrorInfo
+}
+
+func (e *UnsupportedTypeError) Unwrap() error        { return e.ErrorInfo }
+func (e *UnsupportedTypeError) Is(target error) bool { return target == ErrUnsupportedType }
+
+// DepthError is returned when the nesting of the input exceeds [MAX_DEPTH].
+type DepthError struct {
+	*ErrorInfo
+}
+
+func (e *DepthError) Unwrap() error        { return e.ErrorInfo }
+func (e *DepthError) Is(target error) bool { return target == ErrDepth }
+
+// ScopeError describes a field, which can't be decoded in the context and operation of the decoder.
+// It's reported as [FieldError.Err] in [Config.Strict] mode.
+type ScopeError struct {
+	*ErrorInfo
+	Context   scopes.Context
+	Operation scopes.Decoding
+}
+
+func (e *ScopeError) Unwrap() error        { return e.ErrorInfo }
+func (e *ScopeError) Is(target error) bool { return target == ErrScope }
+
+// UnmarshalerError wraps an error returned by a custom unmarshaler, see [Unmarshaler], [TokenUnmarshaler], [json.Unmarshaler] and [encoding.TextUnmarshaler].
+type UnmarshalerError struct {
+	*ErrorInfo
+	// Err is the error returned by the unmarshaler.
+	Err error
+}
+
+func (e *UnmarshalerError) Unwrap() []error      { return []error{e.ErrorInfo, e.Err} }
+func (e *UnmarshalerError) Is(target error) bool { return target == ErrUnmarshaler }
+
+// newError creates a base error at the current position.
+func (d *Decoder) newError(msg string) *Error {
+	e := &Error{
+		Message: msg,
+		Offset:  int(d.pos),
+	}
+	areaStart := e.Offset - 20
+	if areaStart < 0 {
+		areaStart = 0
+		e.AreaPos = e.Offset
+	} else {
+		e.AreaPos = 20
+	}
+	areaEnd := e.Offset + 20
+	if areaEnd > len(d.Buf) {
+		areaEnd = len(d.Buf)
+	}
+	// Copy the area, because the buffer is reused by pooled decoders.
+	e.Area = bytes.Clone(d.Buf[areaStart:areaEnd])
+	e.Line, e.Column = d.lineColumn(e.Offset)
+	return e
+}
+
+// TypeError returns a [*TypeError] at the current position.
+func (d *Decoder) TypeError(msg string) error {
+	return &TypeError{d.newError(msg)}
+}
+
+// mismatchError returns an error for the value at the current position, which kind can't be decoded into the Go type, e.g. a string into a struct.
+// It's a [*TypeError] if the value is a valid JSON, otherwise a [*SyntaxError].
+func (d *Decoder) mismatchError(msg string) error {
+	e := d.newError(msg)
+	if d.validAt(d.pos) {
+		return &TypeError{e}
+	}
+	return &SyntaxError{e}
+}
+
+// quotedError handles an error of decoding the content of a quoted value, which starts at the given offset.
+// The content isn't a JSON, so its syntax errors are type errors if the quoted value is a valid string.
+func (d *Decoder) quotedError(err error, start int64) error {
+	se, ok := err.(*SyntaxError)
+	if ok && d.validAt(start) {
+		return &TypeError{se.ErrorInfo}
+	}
+	return err
+}
+
+// validAt reports whether a valid JSON value starts at the given offset. It doesn't move the decoder.
+func (d *Decoder) validAt(start int64) bool {
+	pos, depth := d.pos, d.depth
+	d.pos = start
+	valid := d.skipValid(depth)
+	d.pos, d.depth = pos, depth
+	return valid
+}
+
+// UnsupportedTypeError returns an [*UnsupportedTypeError] for the type at the current position.
+func (d *Decoder) UnsupportedTypeError(msg string, t reflect.Type) error {
+	e := d.newError(msg)
+	e.Type = t
+	return &UnsupportedTypeError{e}
+}
+
+// DepthError returns a [*DepthError] at the current position.
+func (d *Decoder) DepthError(msg string) error {
+	return &DepthError{d.newError(msg)}
+}
+
+// unmarshalerError wraps an error of a custom unmarshaler of the value, which starts at the given offset.
+// Errors of the decoder itself, e.g. returned by [Decoder.ReadInt64] in [TokenUnmarshaler], are returned as is.
+func (d *Decoder) unmarshalerError(err error, start int64) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	pos := d.pos
+	d.pos = start
+	ue := &UnmarshalerError{ErrorInfo: d.newError("[Blaze unmarshaler] " + err.Error()), Err: err}
+	d.pos = pos
+	return ue
+}
+
+// valueError handles an error of decoding the value v, which starts at the given offset.
+// depth is the depth of the decoder at the beginning of the value.
+// field and parent are the struct field and the struct type, if the value is a struct field.
+//
+// It sets the type and the field to the error, if they are not set yet by a deeper value. Errors, which already have the type, are returned as is.
+// Syntax errors of values, which are valid JSON, are converted to [*TypeError], e.g. a string for an int field.
+// In [Config.CollectErrors] mode type and unmarshaler errors are recorded as [FieldError] and the value is skipped, so decoding can continue.
+// It returns nil if the decoder recovered from the error.
+func (d *Decoder) valueError(err error, v reflect.Value, start int64, depth int, field *types.StructField, parent reflect.Type) error {
+	var e *Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	if field != nil && e.Field == "" {
+		e.Field = field.Field.TitleCase
+		if parent.Name() != "" {
+			e.Field = parent.Name() + "." + e.Field
+		}
+	}
+	// The error is already handled by a deeper value.
+	if e.Type != nil {
+		return err
+	}
+	e.Type = v.Type()
+	_, isType := err.(*TypeError)
+	_, isUnmarshaler := err.(*UnmarshalerError)
+	if !d.config.CollectErrors || !(isType || isUnmarshaler) {
+		return err
+	}
+	end := d.pos
+	d.pos = start
+	kind := d.PeekKind()
+	valid := d.skipValid(depth)
+	d.depth = depth
+	if !valid {
+		d.pos = end
+		return err
+	}
+	msg := "can't decode " + kind.String() + " into " + v.Type().String()
+	if isUnmarshaler {
+		msg = e.Message
+	}
+	d.fieldErrors = append(d.fieldErrors, &FieldError{
+		Path:    e.Path,
+		Reason:  REASON_TYPE,
+		Message: msg,
+		Type:    v.Type().String(),
+		Raw:     string(d.Buf[start:d.pos]),
+		Err:     err,
+	})
+	return nil
+}
+
+// skipValid skips the next value and reports whether it's a valid JSON value.
+// Unlike [Decoder.Skip] it checks the whole structure of objects and arrays.
+func (d *Decoder) skipValid(depth int) bool {
+	d.SkipWhitespace()
+	c := d.char()
+	switch c {
+	case '{', '[':
+		if depth >= MAX_DEPTH {
+			return false
+		}
+		end := byte('}')
+		if c == '[' {
+			end = ']'
+		}
+		d.pos++
+		d.SkipWhitespace()
+		if d.char() == end {
+			d.pos++
+			return true
+		}
+		for {
+			if c == '{' {
+				d.SkipWhitespace()
+				if d.char() != '"' || d.SkipString() != nil {
+					return false
+				}
+				d.SkipWhitespace()
+				if d.char() != ':' {
+					return false
+				}
+				d.pos++
+			}
+			if !d.skipValid(depth + 1) {
+				return false
+			}
+			d.SkipWhitespace()
+			switch d.char() {
+			case ',':
+				d.pos++
+			case end:
+				d.pos++
+				return true
+			default:
+				return false
+			}
+		}
+	case TERMINATION_CHAR:
+		return false
+	default:
+		return d.Skip() == nil
+	}
+}
@@ -0,0 +1,150 @@
+package blaze
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/deveox/blaze/decoder"
+	"github.com/deveox/blaze/encoder"
+)
+
+// Set returns a copy of the JSON document with the value at the given path replaced by v, encoded with [AdminEncoder].
+// See [SetScoped] for details.
+func Set(data []byte, path string, v any) ([]byte, error) {
+	return SetScoped(data, path, v, AdminEncoder)
+}
+
+// SetScoped returns a copy of the JSON document with the value at the given path replaced by v, encoded with the given encoder config.
+// Only the span of the replaced value is changed, the rest of the document (key order, formatting, numbers) is preserved.
+//
+// If the path doesn't exist, the missing object keys are created. A missing array element can be created only at the end of the array,
+// i.e. its index must be equal to the length of the array.
+func SetScoped(data []byte, path string, v any, c *encoder.Config) ([]byte, error) {
+	return set(data, path, func(buf *bytes.Buffer) error {
+		return c.MarshalTo(buf, v)
+	})
+}
+
+// SetRaw works like [Set], but inserts already encoded JSON value as is.
+func SetRaw(data []byte, path string, value []byte) ([]byte, error) {
+	return set(data, path, func(buf *bytes.Buffer) error {
+		buf.Write(value)
+		return nil
+	})
+}
+
+func set(data []byte, path string, write func(buf *bytes.Buffer) error) ([]byte, error) {
+	loc, err := AdminDecoder.Locate(data, path)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	buf.Grow(len(data) + 64)
+	if loc.Found() {
+		buf.Write(data[:loc.Start])
+		if err := write(buf); err != nil {
+			return nil, err
+		}
+		buf.Write(data[loc.End:])
+		return buf.Bytes(), nil
+	}
+
+	part, rest, _ := strings.Cut(loc.Rest, ".")
+	container := data[loc.Start:loc.End]
+	switch container[0] {
+	case '{':
+	case '[':
+		i, err := strconv.Atoi(part)
+		if err != nil || i != loc.Len {
+			return nil, errors.New("[Blaze Set()] array index out of range: " + part)
+		}
+	default:
+		return nil, errors.New("[Blaze Set()] can't set a key of a non-object value: " + part)
+	}
+	// Insert the new member right after the last member of the container.
+	at := loc.End - 2
+	for isSpace(data[at]) {
+		at--
+	}
+	at++
+	buf.Write(data[:at])
+	if at > loc.Start+1 {
+		buf.WriteByte(',')
+	}
+	if container[0] == '{' {
+		if err := writeKey(buf, part); err != nil {
+			return nil, err
+		}
+	}
+	// Create objects for the rest of the path.
+	depth := 0
+	for rest != "" {
+		part, rest, _ = strings.Cut(rest, ".")
+		buf.WriteByte('{')
+		if err := writeKey(buf, part); err != nil {
+			return nil, err
+		}
+		depth++
+	}
+	if err := write(buf); err != nil {
+		return nil, err
+	}
+	for ; depth > 0; depth-- {
+		buf.WriteByte('}')
+	}
+	buf.Write(data[at:])
+	return buf.Bytes(), nil
+}
+
+func writeKey(buf *bytes.Buffer, key string) error {
+	if err := AdminEncoder.MarshalTo(buf, key); err != nil {
+		return err
+	}
+	return buf.WriteByte(':')
+}
+
+// Delete returns a copy of the JSON document with the value at the given path removed, together with its key or array slot.
+// It returns [decoder.ErrNotFound] if the path doesn't exist.
+func Delete(data []byte, path string) ([]byte, error) {
+	if path == "" {
+		return nil, errors.New("[Blaze Delete()] can't delete the root value")
+	}
+	loc, err := AdminDecoder.Locate(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if !loc.Found() {
+		return nil, decoder.ErrNotFound
+	}
+	start, end := loc.Member, loc.End
+	// Remove the following separator, or the preceding one if the member is the last.
+	next := skipSpace(data, end)
+	if next < len(data) && data[next] == ',' {
+		end = skipSpace(data, next+1)
+	} else {
+		prev := start - 1
+		for prev >= 0 && isSpace(data[prev]) {
+			prev--
+		}
+		if data[prev] == ',' {
+			start = prev
+		}
+	}
+	res := make([]byte, 0, len(data)-(end-start))
+	res = append(res, data[:start]...)
+	res = append(res, data[end:]...)
+	return res, nil
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && isSpace(data[i]) {
+		i++
+	}
+	return i
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
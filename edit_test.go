@@ -0,0 +1,106 @@
+package blaze
+
+import (
+	"testing"
+
+	"github.com/deveox/blaze/decoder"
+	"github.com/deveox/blaze/encoder"
+	"github.com/deveox/blaze/scopes"
+	"github.com/stretchr/testify/require"
+)
+
+var editJSON = []byte(`{
+	"b": 1.50,
+	"a": {"x": [1, 2, 3], "y": {}},
+	"z": []
+}`)
+
+type EditUser struct {
+	Name   string
+	Secret string `blaze:"client:-"`
+}
+
+func TestSet(t *testing.T) {
+	res, err := Set(editJSON, "b", 2)
+	require.NoError(t, err)
+	require.Equal(t, `{
+	"b": 2,
+	"a": {"x": [1, 2, 3], "y": {}},
+	"z": []
+}`, string(res))
+
+	res, err = Set(editJSON, "a.x.1", map[string]int{"k": 1})
+	require.NoError(t, err)
+	require.Equal(t, `{
+	"b": 1.50,
+	"a": {"x": [1, {"k":1}, 3], "y": {}},
+	"z": []
+}`, string(res))
+
+	res, err = Set(editJSON, "a.y.new.deep", "v")
+	require.NoError(t, err)
+	require.Equal(t, `{
+	"b": 1.50,
+	"a": {"x": [1, 2, 3], "y": {"new":{"deep":"v"}}},
+	"z": []
+}`, string(res))
+
+	res, err = Set(editJSON, "c", true)
+	require.NoError(t, err)
+	require.Equal(t, `{
+	"b": 1.50,
+	"a": {"x": [1, 2, 3], "y": {}},
+	"z": [],"c":true
+}`, string(res))
+
+	res, err = Set(editJSON, "a.x.3", 4)
+	require.NoError(t, err)
+	require.Contains(t, string(res), `"x": [1, 2, 3,4]`)
+
+	res, err = Set(editJSON, "z.0", 4)
+	require.NoError(t, err)
+	require.Contains(t, string(res), `"z": [4]`)
+
+	_, err = Set(editJSON, "a.x.5", 4)
+	require.Error(t, err)
+	_, err = Set(editJSON, "b.c", 4)
+	require.Error(t, err)
+	_, err = Set([]byte(`{"b":1}`), "b.c", 4)
+	require.Error(t, err)
+
+	clientEncoder := &encoder.Config{Scope: scopes.CONTEXT_CLIENT}
+	res, err = SetScoped([]byte(`{"user":null}`), "user", &EditUser{Name: "n", Secret: "s"}, clientEncoder)
+	require.NoError(t, err)
+	require.Equal(t, `{"user":{"name":"n"}}`, string(res))
+
+	res, err = SetRaw([]byte(`[1,2]`), "1", []byte(`{"raw":true}`))
+	require.NoError(t, err)
+	require.Equal(t, `[1,{"raw":true}]`, string(res))
+}
+
+func TestDelete(t *testing.T) {
+	res, err := Delete(editJSON, "b")
+	require.NoError(t, err)
+	require.Equal(t, `{
+	"a": {"x": [1, 2, 3], "y": {}},
+	"z": []
+}`, string(res))
+
+	res, err = Delete(editJSON, "z")
+	require.NoError(t, err)
+	require.Equal(t, `{
+	"b": 1.50,
+	"a": {"x": [1, 2, 3], "y": {}}
+}`, string(res))
+
+	res, err = Delete(editJSON, "a.x.1")
+	require.NoError(t, err)
+	require.Contains(t, string(res), `"x": [1, 3]`)
+
+	res, err = Delete([]byte(`{"a":[1]}`), "a.0")
+	require.NoError(t, err)
+	require.Equal(t, `{"a":[]}`, string(res))
+
+	_, err = Delete(editJSON, "a.missing")
+	require.ErrorIs(t, err, decoder.ErrNotFound)
+}
@@ -1,24 +1,30 @@
 package encoder
 
 import (
+	"io"
 	"sync"
 
 	"github.com/deveox/blaze/ctx"
 	"github.com/deveox/blaze/scopes"
+	"github.com/deveox/blaze/types"
 )
 
 type Config struct {
 	Scope scopes.Context
-	pool  sync.Pool
+	// Policies are runtime field policies checked in addition to the field scopes, see [types.Policies].
+	Policies *types.Policies
+	pool     sync.Pool
 }
 
 func (c *Config) NewEncoder() *Encoder {
 	if v := c.pool.Get(); v != nil {
 		e := v.(*Encoder)
 		e.bytes = e.bytes[:0]
+		e.flushed = 0
 		e.depth = 0
 		e.fields.short = false
 		e.fields.enabled = false
+		e.fields.within = false
 		if len(e.fields.fields) > 0 {
 			e.fields.fields = e.fields.fields[:0]
 		}
@@ -42,6 +48,11 @@ func (c *Config) MarshalCtx(v any, ctx *ctx.Ctx) ([]byte, error) {
 	return e.marshal(v)
 }
 
+// MarshalPartial encodes only the given fields of the value, and its fields tagged with `blaze:"short"` if short is true.
+// Fields are dot-separated paths, e.g. "address.city". Path segments can be "*", which matches any field, or "**", which matches any number of nested fields.
+// Fields of elements of slices and arrays are selected by the path of the collection, e.g. "orders.id", while keys of maps are segments of the path,
+// e.g. "settings.theme", and "*" matches any key, e.g. "byId.*.total".
+// Fields prefixed with "-" are excluded, e.g. []string{"-avatarUrl"} with short set to true encodes the short view without the avatar URL.
 func (c *Config) MarshalPartial(v any, fields []string, short bool) ([]byte, error) {
 	e := c.NewEncoder()
 	defer c.Return(e)
@@ -58,6 +69,25 @@ func (c *Config) MarshalPartialCtx(v any, fields []string, short bool, ctx *ctx.
 	return e.marshal(v)
 }
 
+// MarshalTo encodes the value and writes it to w.
+// The encoded data is flushed to w in chunks, so the whole document is never held in memory.
+// If an error occurs, the chunks already written stay in w, so the output is an incomplete JSON document.
+func (c *Config) MarshalTo(w io.Writer, v any) error {
+	e := c.NewEncoder()
+	defer c.Return(e)
+	e.Ctx.Clear()
+	return e.marshalTo(w, v, false)
+}
+
+// MarshalPartialTo works like [Config.MarshalPartial], but writes the encoded data to w.
+func (c *Config) MarshalPartialTo(w io.Writer, v any, fields []string, short bool) error {
+	e := c.NewEncoder()
+	defer c.Return(e)
+	e.Ctx.Clear()
+	e.fields.Init(fields, short)
+	return e.marshalTo(w, v, false)
+}
+
 func (c *Config) Return(e *Encoder) {
 	c.pool.Put(e)
 }
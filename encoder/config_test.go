@@ -1,10 +1,13 @@
 package encoder
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/deveox/blaze/ctx"
 	"github.com/deveox/blaze/internal/testdata"
 	"github.com/deveox/blaze/scopes"
+	"github.com/deveox/blaze/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -70,3 +73,68 @@ func TestScope_Admin(t *testing.T) {
 	expected := AddIndent([]byte(`{"name":"test","noDb":true,"read":true,"readCreate":true,"readUpdate":true,"noClient":true,"clientRead":true,"clientReadCreate":true,"clientReadUpdate":true,"clientUpdate":true,"clientCreate":true,"clientWrite":true,"adminRead":true,"adminReadCreate":true,"adminReadUpdate":true}`))
 	require.Equal(t, string(expected), string(res))
 }
+
+type PolicyUser struct {
+	ID     int
+	Email  string
+	Salary int
+}
+
+func newPolicyEncoder(t *testing.T) *Config {
+	p := &types.Policies{}
+	err := p.Set(reflect.TypeFor[PolicyUser](), "email", func(c *ctx.Ctx, parent reflect.Value, f *types.Field) bool {
+		_, userID := c.Get("userID")
+		return parent.Interface().(PolicyUser).ID == userID
+	})
+	require.NoError(t, err)
+	err = p.Set(reflect.TypeFor[PolicyUser](), "salary", func(c *ctx.Ctx, parent reflect.Value, f *types.Field) bool {
+		_, role := c.Get("role")
+		return role == "hr"
+	})
+	require.NoError(t, err)
+	require.Error(t, p.Set(reflect.TypeFor[PolicyUser](), "missing", nil))
+	require.Error(t, p.Set(reflect.TypeFor[int](), "id", nil))
+	return &Config{Scope: scopes.CONTEXT_CLIENT, Policies: p}
+}
+
+func TestPolicies(t *testing.T) {
+	e := newPolicyEncoder(t)
+	users := []PolicyUser{{ID: 1, Email: "a@b.c", Salary: 10}, {ID: 2, Email: "d@e.f", Salary: 20}}
+
+	c := &ctx.Ctx{}
+	c.Set("userID", 1)
+	res, err := e.MarshalCtx(users, c)
+	require.NoError(t, err)
+	require.Equal(t, `[{"id":1,"email":"a@b.c"},{"id":2}]`, string(res))
+
+	c.Set("role", "hr")
+	res, err = e.MarshalCtx(users, c)
+	require.NoError(t, err)
+	require.Equal(t, `[{"id":1,"email":"a@b.c","salary":10},{"id":2,"salary":20}]`, string(res))
+
+	res, err = clientEncoder.Marshal(users)
+	require.NoError(t, err)
+	require.Equal(t, `[{"id":1,"email":"a@b.c","salary":10},{"id":2,"email":"d@e.f","salary":20}]`, string(res))
+}
+
+var publicEncoder = &Config{
+	Scope: scopes.Register("public", scopes.CONTEXT_NONE),
+}
+
+type PublicUser struct {
+	Name     string
+	Password string `blaze:"admin:read.write,client:-"`
+	Email    string `blaze:"read,client:-"`
+}
+
+func TestScope_Public(t *testing.T) {
+	// Contexts without a parent use only scopes without a context name.
+	s := newScopedStruct()
+	res, err := publicEncoder.Marshal(s)
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"test","noDb":true,"read":true,"readCreate":true,"readUpdate":true}`, string(res))
+
+	res, err = publicEncoder.Marshal(PublicUser{Name: "n", Password: "secret", Email: "e"})
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"n","email":"e"}`, string(res))
+}
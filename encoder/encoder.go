@@ -3,7 +3,9 @@ package encoder
 import (
 	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 
 	"github.com/deveox/blaze/ctx"
 	"github.com/deveox/blaze/scopes"
@@ -19,6 +21,10 @@ type Encoder struct {
 	fields    *fields
 	anonymous bool
 	keep      bool
+	// Underlying writer of a streaming encoder, nil otherwise.
+	w io.Writer
+	// Number of bytes already written to w.
+	flushed int
 }
 
 // GetCurrentPath will return the path of the current field being encoded if encoder is created by MarshalPartial
@@ -60,7 +66,11 @@ func (e *Encoder) EncodePartial(v any, fields []string, short int) error {
 	}
 	if e.fields.currentPath != "" {
 		for _, f := range fields {
-			e.fields.fields = append(e.fields.fields, e.fields.currentPath+"."+f)
+			if strings.HasPrefix(f, "-") {
+				e.fields.fields = append(e.fields.fields, "-"+e.fields.currentPath+"."+f[1:])
+			} else {
+				e.fields.fields = append(e.fields.fields, e.fields.currentPath+"."+f)
+			}
 		}
 	} else {
 		e.fields.fields = append(e.fields.fields, fields...)
@@ -90,25 +100,50 @@ func (e *Encoder) marshal(v any) ([]byte, error) {
 	return res, nil
 }
 
-func (e *Encoder) encode(v reflect.Value) error {
-	if e.depth > MAX_DEPTH {
-		return e.ErrorF("exceeded max depth of %d", MAX_DEPTH)
+func (e *Encoder) marshalTo(w io.Writer, v any, newline bool) error {
+	e.w = w
+	defer func() {
+		e.w = nil
+	}()
+	err := e.encode(reflect.ValueOf(v))
+	if err != nil {
+		e.bytes = e.bytes[:0]
+		e.flushed = 0
+		return err
 	}
+	if newline {
+		e.WriteByte('\n')
+	}
+	return e.writeTo(w)
+}
+
+func (e *Encoder) encode(v reflect.Value) error {
 	if !v.IsValid() {
 		e.WriteString("null")
 		return nil
 	}
+	if e.depth > MAX_DEPTH {
+		return &DepthError{&Error{Message: fmt.Sprintf("[Blaze encode()] exceeded max depth of %d", MAX_DEPTH), Type: v.Type()}}
+	}
 	err := getEncoderFn(v.Type())(e, v)
 	e.anonymous = false
+	if err != nil {
+		var ee *Error
+		if errors.As(err, &ee) && ee.Type == nil {
+			ee.Type = v.Type()
+		}
+	}
 	return err
 }
 
+// Error returns an [*Error] with the message.
 func (e *Encoder) Error(msg string) error {
-	return errors.New(msg)
+	return &Error{Message: msg}
 }
 
+// ErrorF returns an [*Error] with the formatted message.
 func (e *Encoder) ErrorF(format string, args ...interface{}) error {
-	return fmt.Errorf(format, args...)
+	return &Error{Message: fmt.Sprintf(format, args...)}
 }
 
 func AddIndent(b []byte) []byte {
@@ -158,5 +193,5 @@ func encodePtr(e *Encoder, v reflect.Value) error {
 }
 
 func encodeUnsupported(e *Encoder, v reflect.Value) error {
-	return e.ErrorF("[blaze encodeUnsupported()] unsupported type: %s", v.Type())
+	return &UnsupportedTypeError{&Error{Message: fmt.Sprintf("[Blaze encodeUnsupported()] unsupported type: %s", v.Type()), Type: v.Type()}}
 }
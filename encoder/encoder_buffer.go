@@ -1,9 +1,19 @@
 package encoder
 
+import "io"
+
+// FLUSH_THRESHOLD is the size of the buffer after which a streaming encoder writes the encoded data to the underlying writer.
+const FLUSH_THRESHOLD = 2048
+
 func (e *Encoder) Reset() {
 	e.bytes = e.bytes[:0]
 }
 
+// Bytes returns the encoded data in the buffer. The slice is valid only until the next use of the encoder.
+func (e *Encoder) Bytes() []byte {
+	return e.bytes
+}
+
 func (e *Encoder) Write(b []byte) {
 	e.bytes = append(e.bytes, b...)
 }
@@ -15,3 +25,33 @@ func (e *Encoder) WriteString(s string) {
 func (e *Encoder) WriteByte(b byte) {
 	e.bytes = append(e.bytes, b)
 }
+
+// Len returns the number of bytes encoded so far, including the bytes already flushed to the underlying writer.
+func (e *Encoder) Len() int {
+	return e.flushed + len(e.bytes)
+}
+
+// flush writes the encoded data to the underlying writer if the encoder is streaming and the buffer exceeds [FLUSH_THRESHOLD].
+// It must be called only right after a ',' separator of a non-empty element is written:
+// at that point none of the previous bytes can be trimmed by the container encoders anymore, except the separator itself, which is kept in the buffer.
+func (e *Encoder) flush() error {
+	if e.w == nil || len(e.bytes) < FLUSH_THRESHOLD {
+		return nil
+	}
+	n := len(e.bytes) - 1
+	if _, err := e.w.Write(e.bytes[:n]); err != nil {
+		return err
+	}
+	e.flushed += n
+	e.bytes[0] = e.bytes[n]
+	e.bytes = e.bytes[:1]
+	return nil
+}
+
+// writeTo writes the rest of the buffer to w and resets the encoder.
+func (e *Encoder) writeTo(w io.Writer) error {
+	_, err := w.Write(e.bytes)
+	e.bytes = e.bytes[:0]
+	e.flushed = 0
+	return err
+}
@@ -18,5 +18,5 @@ func encodePtrCustom(e *Encoder, v reflect.Value) error {
 
 func encodeCustom(e *Encoder, v reflect.Value) error {
 	m := v.Interface().(Marshaler)
-	return m.MarshalBlaze(e)
+	return marshalerError(m.MarshalBlaze(e), v.Type())
 }
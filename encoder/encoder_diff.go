@@ -0,0 +1,368 @@
+package encoder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/deveox/blaze/ctx"
+	"github.com/deveox/blaze/types"
+)
+
+// Diff is a difference between the JSON representations of two values, see [Config.Diff].
+// It can be rendered as a JSON Merge Patch (RFC 7386) with [Diff.MergePatch] or as a JSON Patch (RFC 6902) with [Diff.JSONPatch].
+type Diff struct {
+	root diffNode
+}
+
+// diffNode is a changed value. Either op is set, or the value has changed children.
+type diffNode struct {
+	// key is the object key or the array index of the value in its parent.
+	key string
+	// op is "add", "replace" or "remove", or empty if only children of the value are changed.
+	op string
+	// value is the encoded new value of "add" and "replace".
+	value []byte
+	// array is the encoded new value of an array with changed elements, because arrays can't be merged by a merge patch.
+	array    []byte
+	children []*diffNode
+}
+
+// Diff compares two values of the same type and returns their difference.
+// Values are compared field by field as they are encoded in the context of the config: only readable fields are compared,
+// omitted empty fields are absent, and values with custom marshalers are compared by their encoded JSON.
+// Slices of different lengths are replaced as a whole.
+func (c *Config) Diff(old, new any) (*Diff, error) {
+	e := c.NewEncoder()
+	defer c.Return(e)
+	e.Ctx.Clear()
+	return e.diffValues(old, new)
+}
+
+// DiffCtx sets the [*ctx.Ctx] and compares two values of the same type, see [Config.Diff].
+func (c *Config) DiffCtx(old, new any, ctx *ctx.Ctx) (*Diff, error) {
+	e := c.NewEncoder()
+	defer c.Return(e)
+	e.Ctx = ctx
+	return e.diffValues(old, new)
+}
+
+// Empty reports whether the values are equal.
+func (d *Diff) Empty() bool {
+	return d.root.op == "" && len(d.root.children) == 0
+}
+
+// MergePatch returns the difference as a JSON Merge Patch (RFC 7386).
+// Merge patches can't express null values, values set to null are removed, like by [decoder.Config.ApplyMergePatch].
+func (d *Diff) MergePatch() []byte {
+	e := &Encoder{}
+	d.root.writeMerge(e)
+	return e.bytes
+}
+
+// JSONPatch returns the difference as a JSON Patch (RFC 6902) with "add", "replace" and "remove" operations.
+func (d *Diff) JSONPatch() []byte {
+	e := &Encoder{}
+	e.WriteByte('[')
+	d.root.writeOps(e, "")
+	e.WriteByte(']')
+	return e.bytes
+}
+
+func (n *diffNode) writeMerge(e *Encoder) {
+	switch {
+	case n.op == "remove":
+		e.WriteString("null")
+	case n.op != "":
+		e.Write(n.value)
+	case n.array != nil:
+		e.Write(n.array)
+	default:
+		e.WriteByte('{')
+		for i, c := range n.children {
+			if i > 0 {
+				e.WriteByte(',')
+			}
+			_ = encodeStringOrBytes(e, c.key)
+			e.WriteByte(':')
+			c.writeMerge(e)
+		}
+		e.WriteByte('}')
+	}
+}
+
+func (n *diffNode) writeOps(e *Encoder, path string) {
+	if n.op == "" {
+		for _, c := range n.children {
+			c.writeOps(e, path+"/"+escapePointer(c.key))
+		}
+		return
+	}
+	if len(e.bytes) > 1 {
+		e.WriteByte(',')
+	}
+	e.WriteString(`{"op":"`)
+	e.WriteString(n.op)
+	e.WriteString(`","path":`)
+	_ = encodeStringOrBytes(e, path)
+	if n.op != "remove" {
+		e.WriteString(`,"value":`)
+		e.Write(n.value)
+	}
+	e.WriteByte('}')
+}
+
+// escapePointer escapes the token of a JSON Pointer (RFC 6901).
+func escapePointer(token string) string {
+	if strings.ContainsAny(token, "~/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+	}
+	return token
+}
+
+func (n *diffNode) add(c *diffNode) {
+	if c.op != "" || len(c.children) > 0 {
+		n.children = append(n.children, c)
+	}
+}
+
+func (e *Encoder) diffValues(old, new any) (*Diff, error) {
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(new)
+	if reflect.TypeOf(old) != reflect.TypeOf(new) {
+		return nil, &Error{Message: fmt.Sprintf("[Blaze Diff()] can't compare values of different types '%T' and '%T'", old, new)}
+	}
+	d := &Diff{}
+	if err := e.diff(&d.root, ov, nv); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (e *Encoder) diff(n *diffNode, old, new reflect.Value) error {
+	if e.depth > MAX_DEPTH {
+		return &DepthError{&Error{Message: fmt.Sprintf("[Blaze Diff()] exceeded max depth of %d", MAX_DEPTH)}}
+	}
+	old, new = indirect(old), indirect(new)
+	if !old.IsValid() || !new.IsValid() || old.Type() != new.Type() || isLeaf(old.Type()) {
+		return e.diffLeaf(n, old, new, false)
+	}
+	e.depth++
+	defer func() {
+		e.depth--
+	}()
+	switch old.Kind() {
+	case reflect.Struct:
+		return e.diffStruct(n, old, new, types.Cache.Get(old.Type()))
+	case reflect.Map:
+		return e.diffMap(n, old, new)
+	case reflect.Slice, reflect.Array:
+		return e.diffArray(n, old, new)
+	default:
+		return e.diffLeaf(n, old, new, false)
+	}
+}
+
+// diffLeaf compares the encoded values. str is true for fields encoded as strings, see [types.Field.StringEncoding].
+func (e *Encoder) diffLeaf(n *diffNode, old, new reflect.Value, str bool) error {
+	o, err := e.encodeDiffValue(old, str)
+	if err != nil {
+		return err
+	}
+	v, err := e.encodeDiffValue(new, str)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(o, v) {
+		n.op = "replace"
+		n.value = v
+	}
+	return nil
+}
+
+// diffMember compares a member of an object, which is present in the JSON representation of the old and the new value if oldOk and newOk are true.
+func (e *Encoder) diffMember(n *diffNode, old, new reflect.Value, oldOk, newOk, str bool) error {
+	switch {
+	case oldOk && newOk:
+		if str {
+			return e.diffLeaf(n, old, new, true)
+		}
+		return e.diff(n, old, new)
+	case oldOk:
+		n.op = "remove"
+	case newOk:
+		v, err := e.encodeDiffValue(new, str)
+		if err != nil {
+			return err
+		}
+		n.op = "add"
+		n.value = v
+	}
+	return nil
+}
+
+func (e *Encoder) diffStruct(n *diffNode, old, new reflect.Value, si *types.Struct) error {
+	for _, fi := range si.Fields {
+		if !fi.Field.CheckEncoderScope(e.config.Scope) {
+			continue
+		}
+		of, nf := fi.Lookup(old), fi.Lookup(new)
+		oldOk := e.config.Policies.Check(e.Ctx, si, old, fi.Field) && e.present(of, fi.Field.KeepEmpty)
+		newOk := e.config.Policies.Check(e.Ctx, si, new, fi.Field) && e.present(nf, fi.Field.KeepEmpty)
+		c := &diffNode{key: fi.Field.Name}
+		if err := e.diffMember(c, of, nf, oldOk, newOk, fi.Field.StringEncoding); err != nil {
+			return withKey(err, fi.Field.Name)
+		}
+		n.add(c)
+	}
+	return nil
+}
+
+func (e *Encoder) diffMap(n *diffNode, old, new reflect.Value) error {
+	if old.IsNil() || new.IsNil() {
+		return e.diffLeaf(n, old, new, false)
+	}
+	keys := make(map[string]reflect.Value, new.Len())
+	for _, m := range []reflect.Value{old, new} {
+		iter := m.MapRange()
+		for iter.Next() {
+			keys[mapKeyString(iter.Key())] = iter.Key()
+		}
+	}
+	names := make([]string, 0, len(keys))
+	for k := range keys {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+	for _, k := range names {
+		ov, nv := old.MapIndex(keys[k]), new.MapIndex(keys[k])
+		c := &diffNode{key: k}
+		if err := e.diffMember(c, ov, nv, ov.IsValid() && e.hasContent(ov), nv.IsValid() && e.hasContent(nv), false); err != nil {
+			return withKey(err, k)
+		}
+		n.add(c)
+	}
+	return nil
+}
+
+func (e *Encoder) diffArray(n *diffNode, old, new reflect.Value) error {
+	if old.Kind() == reflect.Slice && (old.IsNil() || new.IsNil() || old.Len() != new.Len()) {
+		return e.diffLeaf(n, old, new, false)
+	}
+	for i := 0; i < old.Len(); i++ {
+		c := &diffNode{key: strconv.Itoa(i)}
+		if err := e.diff(c, old.Index(i), new.Index(i)); err != nil {
+			return withIndex(err, i)
+		}
+		n.add(c)
+	}
+	if len(n.children) > 0 {
+		v, err := e.encodeDiffValue(new, false)
+		if err != nil {
+			return err
+		}
+		n.array = v
+	}
+	return nil
+}
+
+// encodeDiffValue returns a copy of the encoded value.
+func (e *Encoder) encodeDiffValue(v reflect.Value, str bool) ([]byte, error) {
+	start := len(e.bytes)
+	depth := e.depth
+	// The value is encoded as a top-level one, so its empty objects and arrays are kept.
+	e.depth = 0
+	var err error
+	if str && v.IsValid() {
+		err = encodeString(e, v)
+	} else {
+		err = e.encode(v)
+	}
+	e.depth = depth
+	res := bytes.Clone(e.bytes[start:])
+	e.bytes = e.bytes[:start]
+	return res, err
+}
+
+// present reports whether the struct field is present in the encoded struct. Empty fields are omitted unless keep is true.
+func (e *Encoder) present(v reflect.Value, keep bool) bool {
+	if !v.IsValid() {
+		return false
+	}
+	if v.IsZero() {
+		return keep
+	}
+	return e.hasContent(v)
+}
+
+// hasContent reports whether the value isn't omitted by the encoder as an empty object or array, see [encodeStruct].
+func (e *Encoder) hasContent(v reflect.Value) bool {
+	v = indirect(v)
+	if !v.IsValid() || isLeaf(v.Type()) {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		si := types.Cache.Get(v.Type())
+		for _, fi := range si.Fields {
+			if !fi.Field.CheckEncoderScope(e.config.Scope) || !e.config.Policies.Check(e.Ctx, si, v, fi.Field) {
+				continue
+			}
+			if e.present(fi.Lookup(v), fi.Field.KeepEmpty) {
+				return true
+			}
+		}
+		return false
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if e.hasContent(iter.Value()) {
+				return true
+			}
+		}
+		return false
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if e.hasContent(v.Index(i)) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// indirect dereferences pointers and interfaces. It returns an invalid value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// isLeaf reports whether values of the type are encoded as a whole, without fields, elements or entries, e.g. by a custom marshaler.
+func isLeaf(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array:
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 || t == reflect.TypeFor[json.RawMessage]() {
+			return true
+		}
+	default:
+		return true
+	}
+	ptr := reflect.PointerTo(t)
+	for _, i := range []reflect.Type{marshaler, stdMarshaler, textMarshaler} {
+		if t.Implements(i) || ptr.Implements(i) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type DiffAddress struct {
+	City string
+	Zip  string
+}
+
+type DiffStruct struct {
+	Title    string
+	Address  *DiffAddress
+	Tags     []string
+	Items    []DiffAddress
+	Settings map[string]any
+	Count    int    `blaze:"keep"`
+	Secret   string `blaze:"client:-"`
+}
+
+func newDiffStruct() *DiffStruct {
+	return &DiffStruct{
+		Title:    "old",
+		Address:  &DiffAddress{City: "Paris", Zip: "75001"},
+		Tags:     []string{"a", "b"},
+		Items:    []DiffAddress{{City: "Paris"}, {City: "Lyon"}},
+		Settings: map[string]any{"theme": "dark", "a/b": 1, "nested": map[string]any{"x": 1}},
+		Count:    1,
+		Secret:   "old",
+	}
+}
+
+func TestDiff(t *testing.T) {
+	old, new := newDiffStruct(), newDiffStruct()
+	new.Title = ""
+	new.Address.City = "Lyon"
+	new.Tags = append(new.Tags, "c")
+	new.Items[1].Zip = "69001"
+	new.Settings = map[string]any{"theme": "light", "nested": map[string]any{"x": 1, "y": 2}, "new": true}
+	new.Count = 0
+	new.Secret = "new"
+
+	d, err := clientEncoder.Diff(old, new)
+	require.NoError(t, err)
+	require.False(t, d.Empty())
+	require.JSONEq(t, `{
+		"title": null,
+		"address": {"city": "Lyon"},
+		"tags": ["a", "b", "c"],
+		"items": [{"city": "Paris"}, {"city": "Lyon", "zip": "69001"}],
+		"settings": {"a/b": null, "nested": {"y": 2}, "new": true, "theme": "light"},
+		"count": 0
+	}`, string(d.MergePatch()))
+	require.JSONEq(t, `[
+		{"op": "remove", "path": "/title"},
+		{"op": "replace", "path": "/address/city", "value": "Lyon"},
+		{"op": "replace", "path": "/tags", "value": ["a", "b", "c"]},
+		{"op": "add", "path": "/items/1/zip", "value": "69001"},
+		{"op": "remove", "path": "/settings/a~1b"},
+		{"op": "add", "path": "/settings/nested/y", "value": 2},
+		{"op": "add", "path": "/settings/new", "value": true},
+		{"op": "replace", "path": "/settings/theme", "value": "light"},
+		{"op": "replace", "path": "/count", "value": 0}
+	]`, string(d.JSONPatch()))
+
+	// The secret isn't readable by the client.
+	d, err = clientEncoder.Diff(newDiffStruct(), &DiffStruct{Secret: "new"})
+	require.NoError(t, err)
+	require.NotContains(t, string(d.MergePatch()), "secret")
+	d, err = adminEncoder.Diff(old, new)
+	require.NoError(t, err)
+	require.Contains(t, string(d.JSONPatch()), `{"op":"replace","path":"/secret","value":"new"}`)
+}
+
+func TestDiff_Values(t *testing.T) {
+	d, err := DEncoder.Diff(newDiffStruct(), newDiffStruct())
+	require.NoError(t, err)
+	require.True(t, d.Empty())
+	require.Equal(t, `{}`, string(d.MergePatch()))
+	require.Equal(t, `[]`, string(d.JSONPatch()))
+
+	old := newDiffStruct()
+	new := newDiffStruct()
+	new.Address = nil
+	d, err = DEncoder.Diff(old, new)
+	require.NoError(t, err)
+	require.Equal(t, `[{"op":"remove","path":"/address"}]`, string(d.JSONPatch()))
+	d, err = DEncoder.Diff(new, old)
+	require.NoError(t, err)
+	require.Equal(t, `[{"op":"add","path":"/address","value":{"city":"Paris","zip":"75001"}}]`, string(d.JSONPatch()))
+
+	// An address without content is omitted by the encoder.
+	new.Address = &DiffAddress{}
+	d, err = DEncoder.Diff(old, new)
+	require.NoError(t, err)
+	require.Equal(t, `{"address":null}`, string(d.MergePatch()))
+
+	d, err = DEncoder.Diff(1, 2)
+	require.NoError(t, err)
+	require.Equal(t, `2`, string(d.MergePatch()))
+	require.Equal(t, `[{"op":"replace","path":"","value":2}]`, string(d.JSONPatch()))
+
+	_, err = DEncoder.Diff(1, "2")
+	require.Error(t, err)
+}
@@ -1,10 +1,25 @@
 package encoder
 
+import (
+	"reflect"
+	"strings"
+
+	"github.com/deveox/blaze/types"
+	"github.com/deveox/gu/async"
+)
+
+// fields are selected fields of partial marshaling. A field is a dot-separated path, e.g. "address.city", which selects the field with all its content.
+// Paths go through slices, arrays and maps as described in [Config.MarshalPartial].
+// Segments can be "*", which matches any field, or "**", which matches any number of nested fields.
+// Fields prefixed with "-" are excluded with all their content, e.g. "-address.geo", even if they are short or selected by another field.
+// If only excluded fields are given, all fields are selected, or only short ones if short is true.
 type fields struct {
 	short       bool
 	fields      []string
 	currentPath string
 	enabled     bool
+	// within is set while the value isn't selected itself, but encoded because its nested fields can be selected, see [fields.Within].
+	within bool
 }
 
 func (e *fields) Has(fieldName string, short bool) bool {
@@ -18,11 +33,22 @@ func (e *fields) Has(fieldName string, short bool) bool {
 		} else {
 			e.currentPath = e.currentPath + "." + fieldName
 		}
+		selected := false
+		for _, f := range e.fields {
+			if !strings.HasPrefix(f, "-") {
+				selected = true
+			} else if matchParent(f[1:], e.currentPath) {
+				return false
+			}
+		}
 		if e.short && short {
 			return true
 		}
+		if !selected {
+			return !e.short
+		}
 		for _, f := range e.fields {
-			if f == e.currentPath {
+			if !strings.HasPrefix(f, "-") && matchParent(f, e.currentPath) {
 				return true
 			}
 		}
@@ -31,8 +57,135 @@ func (e *fields) Has(fieldName string, short bool) bool {
 	return short
 }
 
+// Nested reports whether a field nested in the current one is excluded, so the current field can't be encoded as a whole.
+func (e *fields) Nested() bool {
+	for _, f := range e.fields {
+		if strings.HasPrefix(f, "-") && matchPath(f[1:], e.currentPath, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// Within reports whether fields nested in the current one, which isn't selected itself, can be selected, so values of the type must be encoded.
+// In the short mode values are encoded only if they can contain short fields. Interface values must be checked by their dynamic types, see [dynamicType].
+func (e *fields) Within(t reflect.Type) bool {
+	t = elemType(t)
+	if t.Kind() != reflect.Interface && (isLeaf(t) || t.Kind() != reflect.Map && t.Kind() != reflect.Struct) {
+		return false
+	}
+	selected := e.short && hasShort(t)
+	for _, f := range e.fields {
+		if strings.HasPrefix(f, "-") {
+			if matchParent(f[1:], e.currentPath) {
+				return false
+			}
+		} else if !selected && matchPath(f, e.currentPath, true) {
+			selected = true
+		}
+	}
+	return selected
+}
+
+// dynamicType returns the type of the value behind pointers and interfaces, or the type of the value itself if they are nil.
+func dynamicType(v reflect.Value) reflect.Type {
+	for (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v.Type()
+}
+
+// shortTypes caches results of [hasShort].
+var shortTypes = &async.Map[reflect.Type, bool]{}
+
+// hasShort reports whether values of the type can contain fields tagged with `blaze:"short"`. Interfaces can hold any value, so they can.
+func hasShort(t reflect.Type) bool {
+	if ok, found := shortTypes.Load(t); found {
+		return ok
+	}
+	ok := findShort(t, map[reflect.Type]bool{})
+	shortTypes.Store(t, ok)
+	return ok
+}
+
+// findShort implements [hasShort]. Visited types are skipped, because their fields are checked by the first visit.
+// Results of nested types depend on the visited ones, so only the result of the root type is cached.
+func findShort(t reflect.Type, visited map[reflect.Type]bool) bool {
+	t = elemType(t)
+	if visited[t] {
+		return false
+	}
+	visited[t] = true
+	switch {
+	case t.Kind() == reflect.Interface:
+		return true
+	case isLeaf(t):
+		return false
+	case t.Kind() == reflect.Map:
+		return findShort(t.Elem(), visited)
+	case t.Kind() == reflect.Struct:
+		for _, fi := range types.Cache.Get(t).Fields {
+			if fi.Field.Short || findShort(fi.Field.Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// elemType returns the type of values, which fields can be selected: the type itself, or the element type of pointers, slices and arrays.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !isLeaf(t) {
+		t = t.Elem()
+	}
+	return t
+}
+
 func (e *fields) Init(fields []string, short bool) {
 	e.fields = fields
 	e.short = short
 	e.enabled = true
 }
+
+// matchParent reports whether the pattern matches the path or one of its parents, which are selected with all their fields.
+func matchParent(pattern, path string) bool {
+	for i := 0; i < len(path); i++ {
+		if path[i] == '.' && matchPath(pattern, path[:i], false) {
+			return true
+		}
+	}
+	return matchPath(pattern, path, false)
+}
+
+// matchPath reports whether the dot-separated path matches the pattern, see [fields].
+// If nested is true, it also reports whether a path nested in the path can match the pattern.
+func matchPath(pattern, path string, nested bool) bool {
+	for {
+		if pattern == "" {
+			return path == ""
+		}
+		seg, rest, _ := strings.Cut(pattern, ".")
+		if path == "" {
+			return nested || seg == "**" && rest == ""
+		}
+		if seg == "**" {
+			if rest == "" {
+				return true
+			}
+			for p := path; ; {
+				if matchPath(rest, p, nested) {
+					return true
+				}
+				var ok bool
+				if _, p, ok = strings.Cut(p, "."); !ok {
+					return nested
+				}
+			}
+		}
+		name, next, _ := strings.Cut(path, ".")
+		if seg != "*" && seg != name {
+			return false
+		}
+		pattern, path = rest, next
+	}
+}
@@ -30,7 +30,7 @@ func encodeUint(e *Encoder, v reflect.Value) error {
 func (e *Encoder) EncodeFloat(v reflect.Value, bits int) error {
 	f := v.Float()
 	if math.IsInf(f, 0) || math.IsNaN(f) {
-		return fmt.Errorf("unsupported value: %v", f)
+		return &UnsupportedValueError{&Error{Message: fmt.Sprintf("[Blaze EncodeFloat()] unsupported value: %v", f), Type: v.Type()}}
 	}
 
 	// Convert as if by ES6 number to string conversion.
@@ -1,6 +1,10 @@
 package encoder
 
-import "reflect"
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
 
 func (e *Encoder) EncodeMap(v reflect.Value, keyEnc, valueEnc EncoderFn) error {
 	e.depth++
@@ -11,10 +15,18 @@ func (e *Encoder) EncodeMap(v reflect.Value, keyEnc, valueEnc EncoderFn) error {
 	e.WriteByte('{')
 	key := v.Type().Key()
 	iter := v.MapRange()
+	path := e.fields.currentPath
 	for {
 		next := iter.Next()
 		if next {
-			oldLen := len(e.bytes)
+			if e.fields.enabled {
+				// Entries are selected by their keys, they inherit the selection of the map in the short mode, unless the map isn't selected itself.
+				e.fields.currentPath = path
+				if !e.fields.Has(mapKeyString(iter.Key()), !e.fields.within) && !e.fields.Within(dynamicType(iter.Value())) {
+					continue
+				}
+			}
+			oldLen := e.Len()
 			switch key.Kind() {
 			case reflect.String:
 				if err := keyEnc(e, iter.Key()); err != nil {
@@ -28,17 +40,21 @@ func (e *Encoder) EncodeMap(v reflect.Value, keyEnc, valueEnc EncoderFn) error {
 				e.WriteByte('"')
 			}
 			e.WriteByte(':')
-			keyLen := len(e.bytes) - oldLen
-			oldLen = len(e.bytes)
+			keyLen := e.Len() - oldLen
+			oldLen = e.Len()
 			if err := valueEnc(e, iter.Value()); err != nil {
-				return err
+				return withKey(err, fmt.Sprint(iter.Key()))
 			}
-			if len(e.bytes) == oldLen {
+			if e.Len() == oldLen {
 				e.bytes = e.bytes[:len(e.bytes)-keyLen]
 			} else {
 				e.WriteByte(',')
+				if err := e.flush(); err != nil {
+					return err
+				}
 			}
 		} else {
+			e.fields.currentPath = path
 			last := len(e.bytes) - 1
 			switch e.bytes[last] {
 			case '{':
@@ -73,3 +89,17 @@ func newMapEncoder(t reflect.Type) EncoderFn {
 		return e.EncodeMap(v, keyEnc, valueEnc)
 	}
 }
+
+// mapKeyString returns the key of a map entry as a string, e.g. a segment of a partial path or a diff.
+func mapKeyString(k reflect.Value) string {
+	switch k.Kind() {
+	case reflect.String:
+		return k.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(k.Uint(), 10)
+	default:
+		return fmt.Sprint(k.Interface())
+	}
+}
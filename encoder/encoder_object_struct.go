@@ -19,7 +19,7 @@ func encodeStruct(e *Encoder, v reflect.Value, si *types.Struct) error {
 	keep := e.keep
 	for _, fi := range si.Fields {
 		ok := fi.Field.CheckEncoderScope(e.config.Scope)
-		if !ok {
+		if !ok || !e.config.Policies.Check(e.Ctx, si, v, fi.Field) {
 			continue
 		}
 
@@ -64,7 +64,7 @@ func encodeStruct(e *Encoder, v reflect.Value, si *types.Struct) error {
 }
 
 func encodeStructField(e *Encoder, v reflect.Value, fi *types.StructField, kind reflect.Kind) error {
-	enabled := e.fields.enabled
+	enabled, within := e.fields.enabled, e.fields.within
 	if enabled {
 		switch kind {
 		case reflect.Ptr, reflect.Interface:
@@ -78,22 +78,27 @@ func encodeStructField(e *Encoder, v reflect.Value, fi *types.StructField, kind
 			return encodeStructField(e, v, fi, f.Kind())
 		case reflect.Array, reflect.Slice, reflect.Map:
 			if e.fields.Has(fi.Field.Name, fi.Field.Short) {
-				if !fi.Field.Short {
+				if !fi.Field.Short && !e.fields.Nested() {
 					e.fields.enabled = false
 				}
+				e.fields.within = false
+			} else if e.fields.Within(dynamicType(v)) {
+				e.fields.within = true
+			} else {
+				return nil
 			}
 		case reflect.Struct:
 			// Encode full struct if its field name specified
 			if e.fields.Has(fi.Field.Name, fi.Field.Short) {
-				if !fi.Field.Short && fi.Field.Struct != nil {
+				if !fi.Field.Short && fi.Field.Struct != nil && !e.fields.Nested() {
 					e.fields.enabled = false
 				}
+				e.fields.within = false
+			} else if e.fields.Within(dynamicType(v)) {
+				e.fields.within = true
 			} else {
-				if fi.Field.Struct == nil {
-					return nil
-				}
+				return nil
 			}
-
 			// Otherwise, continue to check its fields
 		default:
 			// Skip if field name is not in the partial list
@@ -103,24 +108,28 @@ func encodeStructField(e *Encoder, v reflect.Value, fi *types.StructField, kind
 		}
 	}
 	e.Write(fi.Field.ObjectKey)
-	oldLen := len(e.bytes)
+	oldLen := e.Len()
 	if fi.Field.StringEncoding {
 		if err := encodeString(e, v); err != nil {
-			return err
+			return withKey(err, fi.Field.Name)
 		}
 	} else {
 		if err := e.encode(v); err != nil {
-			return err
+			return withKey(err, fi.Field.Name)
 		}
 	}
-	if len(e.bytes) == oldLen {
+	if e.Len() == oldLen {
 		e.bytes = e.bytes[:len(e.bytes)-len(fi.Field.ObjectKey)]
 	} else {
 		e.WriteByte(',')
+		if err := e.flush(); err != nil {
+			return err
+		}
 	}
 	if enabled != e.fields.enabled {
 		e.fields.enabled = enabled
 	}
+	e.fields.within = within
 	return nil
 }
 
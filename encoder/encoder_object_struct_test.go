@@ -213,6 +213,183 @@ func TestEncode_Partial(t *testing.T) {
 	require.Equal(t, string(wanted), string(bytes))
 }
 
+func TestEncode_Partial_Wildcards(t *testing.T) {
+	v := newPartialStruct()
+	cases := []struct {
+		fields []string
+		short  bool
+		wanted string
+	}{
+		{[]string{"nested.*"}, false, `{"nested":{"short":"short nested","notShort":"not short nested","ignored":"ignored nested"}}`},
+		{[]string{"**"}, false, `{"short":"short","notShort":"not short","ignored":"ignored","partialEmbedded":"embedded","nested":{"short":"short nested","notShort":"not short nested","ignored":"ignored nested"},"shortEmbedded":"short embedded"}`},
+		{[]string{"**", "-ignored", "-nested.notShort"}, false, `{"short":"short","notShort":"not short","partialEmbedded":"embedded","nested":{"short":"short nested","ignored":"ignored nested"},"shortEmbedded":"short embedded"}`},
+		{[]string{"-**.ignored", "-shortEmbedded"}, false, `{"short":"short","notShort":"not short","partialEmbedded":"embedded","nested":{"short":"short nested","notShort":"not short nested"}}`},
+		{[]string{"-partialEmbedded"}, true, `{"short":"short","nested":{"short":"short nested"},"shortEmbedded":"short embedded"}`},
+		{[]string{"nested", "-nested.short"}, false, `{"nested":{"notShort":"not short nested","ignored":"ignored nested"}}`},
+		{[]string{"*.short"}, false, `{"nested":{"short":"short nested"}}`},
+	}
+	for _, c := range cases {
+		bytes, err := DEncoder.MarshalPartial(v, c.fields, c.short)
+		require.NoError(t, err)
+		require.Equal(t, c.wanted, string(bytes), c.fields)
+	}
+}
+
+type PartialGeo struct {
+	Lat float64
+}
+
+type PartialAddress struct {
+	City string
+	Geo  PartialGeo
+}
+
+type PartialUser struct {
+	Name    string
+	Address PartialAddress
+	Home    *PartialAddress
+}
+
+func TestEncode_Partial_ExcludeNested(t *testing.T) {
+	v := &PartialUser{
+		Name:    "n",
+		Address: PartialAddress{City: "Paris", Geo: PartialGeo{Lat: 1}},
+		Home:    &PartialAddress{City: "Lyon", Geo: PartialGeo{Lat: 2}},
+	}
+	cases := []struct {
+		fields []string
+		wanted string
+	}{
+		{[]string{"address", "-address.geo"}, `{"address":{"city":"Paris"}}`},
+		{[]string{"**", "-address.geo"}, `{"name":"n","address":{"city":"Paris"},"home":{"city":"Lyon","geo":{"lat":2}}}`},
+		{[]string{"-address.geo", "-home"}, `{"name":"n","address":{"city":"Paris"}}`},
+		{[]string{"address.geo.lat", "-address.geo"}, `{}`},
+		{[]string{"**", "-*.geo"}, `{"name":"n","address":{"city":"Paris"},"home":{"city":"Lyon"}}`},
+		{[]string{"name", "home.city"}, `{"name":"n","home":{"city":"Lyon"}}`},
+	}
+	for _, c := range cases {
+		bytes, err := DEncoder.MarshalPartial(v, c.fields, false)
+		require.NoError(t, err)
+		require.Equal(t, c.wanted, string(bytes), c.fields)
+	}
+}
+
+type PartialOrder struct {
+	ID    int
+	Total int `blaze:"short"`
+	Items map[int]PartialItem
+}
+
+type PartialItem struct {
+	Name string `blaze:"short"`
+	Qty  int
+}
+
+type PartialCollections struct {
+	Name     string `blaze:"short"`
+	Tags     []string
+	Orders   []*PartialOrder
+	ByID     map[string]PartialOrder
+	Settings map[string]string `blaze:"short"`
+}
+
+func TestEncode_Partial_Collections(t *testing.T) {
+	v := &PartialCollections{
+		Name: "John",
+		Tags: []string{"a", "b"},
+		Orders: []*PartialOrder{
+			{ID: 1, Total: 10, Items: map[int]PartialItem{1: {Name: "x", Qty: 1}}},
+			{ID: 2, Total: 20},
+		},
+		ByID:     map[string]PartialOrder{"a": {ID: 1, Total: 10}},
+		Settings: map[string]string{"theme": "dark"},
+	}
+	cases := []struct {
+		fields []string
+		short  bool
+		wanted string
+	}{
+		{[]string{"name"}, false, `{"name":"John"}`},
+		{[]string{"orders.id"}, false, `{"orders":[{"id":1},{"id":2}]}`},
+		{[]string{"orders.items.*.qty"}, false, `{"orders":[{"items":{"1":{"qty":1}}}]}`},
+		{[]string{"byId.*.total"}, false, `{"byId":{"a":{"total":10}}}`},
+		{[]string{"byId.a.id", "byId.b.id"}, false, `{"byId":{"a":{"id":1}}}`},
+		{[]string{"byId.b"}, false, `{}`},
+		{[]string{"settings.theme"}, false, `{"settings":{"theme":"dark"}}`},
+		{[]string{"settings", "-settings.theme"}, false, `{}`},
+		{[]string{"orders", "-orders.items"}, false, `{"orders":[{"id":1,"total":10},{"id":2,"total":20}]}`},
+		{nil, true, `{"name":"John","orders":[{"total":10,"items":{"1":{"name":"x"}}},{"total":20}],"byId":{"a":{"total":10}},"settings":{"theme":"dark"}}`},
+	}
+	for _, c := range cases {
+		bytes, err := DEncoder.MarshalPartial(v, c.fields, c.short)
+		require.NoError(t, err)
+		require.Equal(t, c.wanted, string(bytes), c.fields)
+	}
+}
+
+type PartialDynamic struct {
+	ID     int `blaze:"short"`
+	Tags   []string
+	Counts map[string]int
+	Any    any
+	Extra  map[string]any
+	Items  map[string]PartialItem
+}
+
+func TestEncode_Partial_Dynamic(t *testing.T) {
+	v := &PartialDynamic{
+		ID:     1,
+		Tags:   []string{"a"},
+		Counts: map[string]int{"a": 1},
+		Any:    map[string]any{"k": 1, "j": map[string]int{"id": 2}},
+		Extra:  map[string]any{"a": 1, "b": PartialItem{Name: "x", Qty: 1}},
+		Items:  map[string]PartialItem{"k": {Name: "y", Qty: 2}},
+	}
+	cases := []struct {
+		fields []string
+		short  bool
+		wanted string
+	}{
+		// Collections of leaves are omitted in the short mode, like non-short fields.
+		{nil, true, `{"id":1,"extra":{"b":{"name":"x"}},"items":{"k":{"name":"y"}}}`},
+		// Values of interfaces are selected by their dynamic types.
+		{[]string{"**.id"}, false, `{"id":1,"any":{"j":{"id":2}}}`},
+		{[]string{"any.k", "extra.b.qty"}, false, `{"any":{"k":1},"extra":{"b":{"qty":1}}}`},
+	}
+	for _, c := range cases {
+		bytes, err := DEncoder.MarshalPartial(v, c.fields, c.short)
+		require.NoError(t, err)
+		require.Equal(t, c.wanted, string(bytes), c.fields)
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	cases := []struct {
+		pattern, path string
+		nested        bool
+		wanted        bool
+	}{
+		{"a.b", "a.b", false, true},
+		{"a.b", "a", false, false},
+		{"a.b", "a", true, true},
+		{"a.b", "a.b.c", false, false},
+		{"a.*", "a.b", false, true},
+		{"a.*", "a.b.c", false, false},
+		{"*", "a", false, true},
+		{"**", "a.b.c", false, true},
+		{"a.**", "a", false, true},
+		{"**.c", "a.b.c", false, true},
+		{"**.c", "c", false, true},
+		{"**.c", "a.b", false, false},
+		{"**.c", "a.b", true, true},
+		{"a.**.d", "a.b.c.d", false, true},
+		{"a.**.d", "b.c.d", false, false},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.wanted, matchPath(c.pattern, c.path, c.nested), c)
+	}
+}
+
 // Benchmarks
 func BenchmarkStruct_Empty_Blaze(b *testing.B) {
 	v := newDataEmpty(5, 10, true)
@@ -0,0 +1,189 @@
+package encoder
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/deveox/blaze/ctx"
+	"github.com/deveox/blaze/types"
+)
+
+// ErrSelection is matched by [SelectionError] with [errors.Is].
+var ErrSelection = errors.New("[Blaze] invalid selection")
+
+// SelectionError is returned by [Config.ParseSelection] when the selection is malformed, or selects a field, which doesn't exist or can't be encoded.
+type SelectionError struct {
+	*ErrorInfo
+	// Offset is the byte offset of the error in the selection.
+	Offset int
+}
+
+func (e *SelectionError) Unwrap() error        { return e.ErrorInfo }
+func (e *SelectionError) Is(target error) bool { return target == ErrSelection }
+
+// Selection is a parsed and validated list of fields for partial marshaling, see [Config.ParseSelection].
+// It's immutable, so it can be parsed once and reused by concurrent requests.
+type Selection struct {
+	fields []string
+}
+
+// Fields returns the selected fields as dot-separated paths accepted by [Config.MarshalPartial], e.g. "address.city".
+func (s *Selection) Fields() []string {
+	return slices.Clone(s.fields)
+}
+
+// ParseSelection parses a GraphQL-like selection of fields of the type, e.g. "id,name,address{city,zip},orders{id,total}".
+// A field without a sub-selection is encoded as a whole. Sub-selections are paths of [Config.MarshalPartial],
+// e.g. "orders{id}" is "orders.id" and "byId{a,*{total}}" is "byId.a" and "byId.*.total".
+// "*" selects any field and "**" any nested field, fields prefixed with "-" are excluded, e.g. "**,-password,address{-geo}", see [Config.MarshalPartial].
+// A sub-selection of only exclusions selects the rest of the field, e.g. "address{-geo}" is "address" and "-address.geo".
+//
+// Fields are selected by their JSON names and must be readable in the scope of the config, otherwise [SelectionError] is returned.
+// Fields of interfaces and sub-selections of wildcards in structs can't be validated, so they are accepted as is.
+func (c *Config) ParseSelection(t reflect.Type, selection string) (*Selection, error) {
+	p := &selectionParser{config: c, data: selection}
+	p.parse(t, "")
+	if p.err == nil && p.pos < len(p.data) {
+		p.fail(fmt.Sprintf("unexpected '%c'", p.data[p.pos]), "")
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	// Clip the slice, so fields appended by the encoder never share its array.
+	return &Selection{fields: slices.Clip(p.fields)}, nil
+}
+
+// MarshalSelection encodes only the selected fields of the value, see [Config.ParseSelection].
+func (c *Config) MarshalSelection(v any, s *Selection) ([]byte, error) {
+	return c.MarshalPartial(v, s.fields, false)
+}
+
+// MarshalSelectionCtx sets the [*ctx.Ctx] and encodes only the selected fields of the value, see [Config.ParseSelection].
+func (c *Config) MarshalSelectionCtx(v any, s *Selection, ctx *ctx.Ctx) ([]byte, error) {
+	return c.MarshalPartialCtx(v, s.fields, false, ctx)
+}
+
+type selectionParser struct {
+	config *Config
+	data   string
+	pos    int
+	fields []string
+	err    error
+}
+
+func (p *selectionParser) fail(msg, path string) {
+	if p.err == nil {
+		p.err = &SelectionError{ErrorInfo: &Error{Message: "[Blaze ParseSelection()] " + msg, Path: path}, Offset: p.pos}
+	}
+}
+
+func (p *selectionParser) skipWhitespace() {
+	for p.pos < len(p.data) && strings.IndexByte(" \t\r\n", p.data[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// parse parses a comma-separated list of fields of the type until the end of the data or a closing brace.
+// An invalid type means that fields can't be validated.
+func (p *selectionParser) parse(t reflect.Type, path string) {
+	si, elem, ok := selectionLevel(t)
+	if !ok {
+		p.fail("can't select fields of '"+t.String()+"'", path)
+		return
+	}
+	for {
+		p.skipWhitespace()
+		start := p.pos
+		for p.pos < len(p.data) && strings.IndexByte(",{} \t\r\n", p.data[p.pos]) < 0 {
+			p.pos++
+		}
+		name := p.data[start:p.pos]
+		exclude := strings.HasPrefix(name, "-")
+		if exclude {
+			name = name[1:]
+		}
+		if name == "" {
+			p.fail("expected a field name", path)
+			return
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		ft := elem
+		if name == "**" {
+			ft = nil
+		} else if si != nil && name != "*" {
+			fi, ok := si.GetField(name)
+			if !ok {
+				p.pos = start
+				p.fail("unknown field '"+name+"'", fieldPath)
+				return
+			}
+			if !fi.Field.CheckEncoderScope(p.config.Scope) {
+				p.pos = start
+				p.fail("field '"+name+"' can't be read", fieldPath)
+				return
+			}
+			ft = fi.Field.Type
+		}
+		p.skipWhitespace()
+		if p.pos < len(p.data) && p.data[p.pos] == '{' {
+			if exclude {
+				p.fail("excluded field '"+name+"' can't have a sub-selection", fieldPath)
+				return
+			}
+			p.pos++
+			n := len(p.fields)
+			p.parse(ft, fieldPath)
+			if p.err != nil {
+				return
+			}
+			// A sub-selection of only exclusions selects the rest of the field.
+			if !slices.ContainsFunc(p.fields[n:], func(f string) bool { return !strings.HasPrefix(f, "-") }) && !slices.Contains(p.fields, fieldPath) {
+				p.fields = slices.Insert(p.fields, n, fieldPath)
+			}
+			if p.pos == len(p.data) || p.data[p.pos] != '}' {
+				p.fail("expected '}'", fieldPath)
+				return
+			}
+			p.pos++
+			p.skipWhitespace()
+		} else {
+			if exclude {
+				fieldPath = "-" + fieldPath
+			}
+			if !slices.Contains(p.fields, fieldPath) {
+				p.fields = append(p.fields, fieldPath)
+			}
+		}
+		if p.pos == len(p.data) || p.data[p.pos] != ',' {
+			return
+		}
+		p.pos++
+	}
+}
+
+// selectionLevel returns the struct, which fields can be selected in values of the type, or the element type of a map, which keys can be selected.
+// Both are nil for interfaces, which fields are known only at runtime. It returns false for types without fields.
+func selectionLevel(t reflect.Type) (*types.Struct, reflect.Type, bool) {
+	if t == nil {
+		return nil, nil, true
+	}
+	t = elemType(t)
+	switch {
+	case t.Kind() == reflect.Interface:
+		return nil, nil, true
+	case isLeaf(t):
+		return nil, nil, false
+	case t.Kind() == reflect.Map:
+		return nil, t.Elem(), true
+	case t.Kind() == reflect.Struct:
+		return types.Cache.Get(t), nil, true
+	default:
+		return nil, nil, false
+	}
+}
@@ -0,0 +1,121 @@
+package encoder
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type SelectionOrder struct {
+	ID    int
+	Total int
+	Note  string
+}
+
+type SelectionAddress struct {
+	City string
+	Zip  string
+}
+
+type SelectionUser struct {
+	ID       int
+	Name     string
+	Address  *SelectionAddress
+	Orders   []SelectionOrder
+	Created  time.Time
+	Extra    any
+	ByID     map[string]SelectionOrder
+	Password string `blaze:"client:-"`
+}
+
+func TestParseSelection(t *testing.T) {
+	typ := reflect.TypeFor[SelectionUser]()
+	s, err := clientEncoder.ParseSelection(typ, " id, name ,address { city , zip },orders{id,total},extra{a{b}},id")
+	require.NoError(t, err)
+	require.Equal(t, []string{"id", "name", "address.city", "address.zip", "orders.id", "orders.total", "extra.a.b"}, s.Fields())
+
+	v := &SelectionUser{
+		ID:       1,
+		Name:     "John",
+		Address:  &SelectionAddress{City: "Paris", Zip: "75001"},
+		Orders:   []SelectionOrder{{ID: 1, Total: 10, Note: "a"}, {ID: 2, Total: 20, Note: "b"}},
+		Password: "secret",
+	}
+	s, err = clientEncoder.ParseSelection(reflect.TypeFor[*SelectionUser](), "name,address{city},orders{id}")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		res, err := clientEncoder.MarshalSelection(v, s)
+		require.NoError(t, err)
+		require.Equal(t, `{"name":"John","address":{"city":"Paris"},"orders":[{"id":1},{"id":2}]}`, string(res))
+	}
+	require.Equal(t, []string{"name", "address.city", "orders.id"}, s.Fields())
+
+	s, err = clientEncoder.ParseSelection(typ, "**,-created,address{-zip},orders{*{x}}")
+	require.NoError(t, err)
+	require.Equal(t, []string{"**", "-created", "address", "-address.zip", "orders.*.x"}, s.Fields())
+	s, err = clientEncoder.ParseSelection(typ, "*,-created,-extra,address{-zip},orders{-note}")
+	require.NoError(t, err)
+	res, err := clientEncoder.MarshalSelection(v, s)
+	require.NoError(t, err)
+	require.Equal(t, `{"id":1,"name":"John","address":{"city":"Paris"},"orders":[{"id":1,"total":10},{"id":2,"total":20}]}`, string(res))
+
+	// A sub-selection of only exclusions selects the rest of the field.
+	s, err = clientEncoder.ParseSelection(typ, "name,address{-zip}")
+	require.NoError(t, err)
+	require.Equal(t, []string{"name", "address", "-address.zip"}, s.Fields())
+	res, err = clientEncoder.MarshalSelection(v, s)
+	require.NoError(t, err)
+	require.Equal(t, `{"name":"John","address":{"city":"Paris"}}`, string(res))
+
+	// Keys of maps are selected by their names.
+	v.ByID = map[string]SelectionOrder{"a": {ID: 1, Total: 10}, "b": {ID: 2, Total: 20}}
+	s, err = clientEncoder.ParseSelection(typ, "byId{a{id},*{total},**{x}}")
+	require.NoError(t, err)
+	require.Equal(t, []string{"byId.a.id", "byId.*.total", "byId.**.x"}, s.Fields())
+	s, err = clientEncoder.ParseSelection(typ, "byId{a{id},b}")
+	require.NoError(t, err)
+	res, err = clientEncoder.MarshalSelection(v, s)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"byId":{"a":{"id":1},"b":{"id":2,"total":20}}}`, string(res))
+}
+
+func TestParseSelection_Errors(t *testing.T) {
+	typ := reflect.TypeFor[SelectionUser]()
+	cases := []struct {
+		selection string
+		offset    int
+		path      string
+	}{
+		{"", 0, ""},
+		{"id,", 3, ""},
+		{"unknown", 0, "unknown"},
+		{"address{country}", 8, "address.country"},
+		{"password", 0, "password"},
+		{"name{a}", 5, "name"},
+		{"created{a}", 8, "created"},
+		{"address{}", 8, "address"},
+		{"address{city", 12, "address"},
+		{"id}", 2, ""},
+		{"id name", 3, ""},
+		{"-address{city}", 8, "address"},
+		{"-", 1, ""},
+		{"byId{a{unknown}}", 7, "byId.a.unknown"},
+		{"byId{*{unknown}}", 7, "byId.*.unknown"},
+		{"byId{a{id{x}}}", 10, "byId.a.id"},
+	}
+	for _, c := range cases {
+		_, err := clientEncoder.ParseSelection(typ, c.selection)
+		var se *SelectionError
+		require.True(t, errors.As(err, &se), c.selection)
+		require.ErrorIs(t, err, ErrSelection)
+		require.Equal(t, c.offset, se.Offset, c.selection)
+		require.Equal(t, c.path, se.Path, c.selection)
+	}
+
+	// Admins can read the password.
+	_, err := adminEncoder.ParseSelection(typ, "password")
+	require.NoError(t, err)
+}
@@ -64,13 +64,16 @@ func (e *Encoder) EncodeSlice(v reflect.Value, valueEnc EncoderFn) (err error) {
 
 	for i := 0; i < n; i++ {
 		f := v.Index(i)
-		oldLen := len(e.bytes)
+		oldLen := e.Len()
 		err = valueEnc(e, f)
 		if err != nil {
-			return err
+			return withIndex(err, i)
 		}
-		if len(e.bytes) != oldLen {
+		if e.Len() != oldLen {
 			e.bytes = append(e.bytes, ',')
+			if err = e.flush(); err != nil {
+				return err
+			}
 		}
 	}
 
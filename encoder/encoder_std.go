@@ -26,7 +26,7 @@ func encodeStd(e *Encoder, v reflect.Value) error {
 	m := v.Interface().(json.Marshaler)
 	b, err := m.MarshalJSON()
 	if err != nil {
-		return err
+		return marshalerError(err, v.Type())
 	}
 	e.Write(b)
 	return nil
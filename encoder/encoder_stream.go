@@ -0,0 +1,58 @@
+package encoder
+
+import (
+	"io"
+
+	"github.com/deveox/blaze/ctx"
+)
+
+// StreamEncoder writes consecutive JSON values to an output stream, each value followed by a newline.
+type StreamEncoder struct {
+	config *Config
+	w      io.Writer
+}
+
+// NewStreamEncoder creates a new encoder that writes to w.
+func (c *Config) NewStreamEncoder(w io.Writer) *StreamEncoder {
+	return &StreamEncoder{config: c, w: w}
+}
+
+// Encode writes the JSON encoding of v to the stream, followed by a newline character.
+// Like [Config.MarshalTo], it may leave an incomplete value in the stream if an error occurs.
+func (s *StreamEncoder) Encode(v any) error {
+	return s.encode(v, nil, nil, false, false)
+}
+
+// EncodeCtx works like [StreamEncoder.Encode], but sets the [*ctx.Ctx] of the encoder.
+func (s *StreamEncoder) EncodeCtx(v any, ctx *ctx.Ctx) error {
+	return s.encode(v, ctx, nil, false, false)
+}
+
+// EncodePartial works like [Config.MarshalPartial], but writes the JSON encoding of v to the stream, followed by a newline character.
+func (s *StreamEncoder) EncodePartial(v any, fields []string, short bool) error {
+	return s.encode(v, nil, fields, short, true)
+}
+
+// EncodePartialCtx works like [StreamEncoder.EncodePartial], but sets the [*ctx.Ctx] of the encoder.
+func (s *StreamEncoder) EncodePartialCtx(v any, fields []string, short bool, ctx *ctx.Ctx) error {
+	return s.encode(v, ctx, fields, short, true)
+}
+
+func (s *StreamEncoder) encode(v any, c *ctx.Ctx, fields []string, short bool, partial bool) error {
+	e := s.config.NewEncoder()
+	defer s.config.Return(e)
+	// Restore own context of the pooled encoder, so the caller's context is never cleared by other calls.
+	own := e.Ctx
+	defer func() {
+		e.Ctx = own
+	}()
+	if c != nil {
+		e.Ctx = c
+	} else {
+		e.Ctx.Clear()
+	}
+	if partial {
+		e.fields.Init(fields, short)
+	}
+	return e.marshalTo(s.w, v, true)
+}
@@ -0,0 +1,84 @@
+package encoder
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type chunkWriter struct {
+	bytes.Buffer
+	chunks int
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.chunks++
+	return w.Buffer.Write(p)
+}
+
+func marshalCopy(t *testing.T, v any) string {
+	res, err := DEncoder.Marshal(v)
+	require.NoError(t, err)
+	return string(res)
+}
+
+func TestMarshalTo(t *testing.T) {
+	v := newDataEmpty(3, 20, true)
+	expected := marshalCopy(t, v)
+	require.Greater(t, len(expected), 2*FLUSH_THRESHOLD)
+
+	w := &chunkWriter{}
+	err := DEncoder.MarshalTo(w, v)
+	require.NoError(t, err)
+	require.Equal(t, expected, w.String())
+	require.Greater(t, w.chunks, 1)
+
+	v2 := newData(3, 20, true)
+	w = &chunkWriter{}
+	err = DEncoder.MarshalTo(w, v2)
+	require.NoError(t, err)
+	require.Equal(t, marshalCopy(t, v2), w.String())
+}
+
+func TestMarshalTo_Trimming(t *testing.T) {
+	// Empty nested values after a flush must still be trimmed.
+	v := [][]*DataEmpty{
+		make([]*DataEmpty, 0),
+		{newDataEmpty(2, 20, false), {}, newDataEmpty(2, 20, false), {}},
+		{{}},
+	}
+	expected := marshalCopy(t, v)
+	w := &chunkWriter{}
+	err := DEncoder.MarshalTo(w, v)
+	require.NoError(t, err)
+	require.Equal(t, expected, w.String())
+	require.Greater(t, w.chunks, 1)
+}
+
+func TestMarshalPartialTo(t *testing.T) {
+	v := newPartialStruct()
+	v.NotShort = strings.Repeat("x", 2*FLUSH_THRESHOLD)
+	fields := []string{"notShort", "nested.notShort"}
+	expected, err := DEncoder.MarshalPartial(v, fields, true)
+	require.NoError(t, err)
+	exp := string(expected)
+
+	w := &chunkWriter{}
+	err = DEncoder.MarshalPartialTo(w, v, fields, true)
+	require.NoError(t, err)
+	require.Equal(t, exp, w.String())
+}
+
+func TestStreamEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	s := DEncoder.NewStreamEncoder(&buf)
+	require.NoError(t, s.Encode(map[string]int{"a": 1}))
+	require.NoError(t, s.Encode([]int{1, 2}))
+	require.NoError(t, s.EncodePartial(newPartialStruct(), nil, true))
+	require.Equal(t, `{"a":1}
+[1,2]
+{"short":"short","partialEmbedded":"embedded","nested":{"short":"short nested"},"shortEmbedded":"short embedded"}
+`, buf.String())
+}
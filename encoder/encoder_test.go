@@ -2,6 +2,9 @@ package encoder
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -41,3 +44,51 @@ func EqualMap(t *testing.T, v interface{}) {
 	require.NoError(t, err)
 	require.Equal(t, v2, v3)
 }
+
+type failingMarshaler struct{ Fail bool }
+
+var errFailing = errors.New("failing")
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) { return nil, errFailing }
+
+type ErrorItem struct {
+	Price float64
+}
+
+type ErrorStruct struct {
+	Items  []ErrorItem
+	Meta   map[string]any
+	Custom failingMarshaler
+}
+
+type cyclic struct {
+	Next *cyclic
+}
+
+func TestError_Kinds(t *testing.T) {
+	_, err := DEncoder.Marshal(ErrorStruct{Items: []ErrorItem{{1}, {math.NaN()}}})
+	require.ErrorIs(t, err, ErrUnsupportedValue)
+	var e *Error
+	require.ErrorAs(t, err, &e)
+	require.Equal(t, "items[1].price", e.Path)
+	require.Equal(t, reflect.TypeFor[float64](), e.Type)
+	require.Contains(t, err.Error(), "(path items[1].price, type float64)")
+
+	_, err = DEncoder.Marshal(ErrorStruct{Meta: map[string]any{"ch": make(chan int)}})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+	var ute *UnsupportedTypeError
+	require.ErrorAs(t, err, &ute)
+	require.Equal(t, "meta.ch", ute.Path)
+
+	_, err = DEncoder.Marshal(ErrorStruct{Custom: failingMarshaler{Fail: true}})
+	require.ErrorIs(t, err, ErrMarshaler)
+	require.ErrorIs(t, err, errFailing)
+	var me *MarshalerError
+	require.ErrorAs(t, err, &me)
+	require.Equal(t, "custom", me.Path)
+
+	c := &cyclic{}
+	c.Next = c
+	_, err = DEncoder.Marshal(c)
+	require.ErrorIs(t, err, ErrDepth)
+}
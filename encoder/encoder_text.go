@@ -22,7 +22,7 @@ func encodeText(e *Encoder, v reflect.Value) error {
 	m := v.Interface().(encoding.TextMarshaler)
 	b, err := m.MarshalText()
 	if err != nil {
-		return err
+		return marshalerError(err, v.Type())
 	}
 	return encodeStringOrBytes(e, b)
 }
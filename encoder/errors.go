@@ -0,0 +1,122 @@
+package encoder
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// Sentinel errors to check the kind of an encoding error with [errors.Is], e.g. errors.Is(err, encoder.ErrUnsupportedType).
+var (
+	ErrUnsupportedType  = errors.New("[Blaze] unsupported type")
+	ErrUnsupportedValue = errors.New("[Blaze] unsupported value")
+	ErrDepth            = errors.New("[Blaze] maximum depth reached")
+	ErrMarshaler        = errors.New("[Blaze] marshaler error")
+)
+
+// Error is a base of all encoding errors. It can be matched with [errors.As] to get the location of the error.
+type Error struct {
+	Message string
+	// Path is a JSON path to the value, which can't be encoded, e.g. "items[3].address.zip".
+	Path string
+	// Type is the Go type of the value, which can't be encoded.
+	Type reflect.Type
+}
+
+func (e *Error) Error() string {
+	str := e.Message
+	if e.Path == "" && e.Type == nil {
+		return str
+	}
+	str += " ("
+	if e.Path != "" {
+		str += "path " + e.Path
+		if e.Type != nil {
+			str += ", "
+		}
+	}
+	if e.Type != nil {
+		str += "type " + e.Type.String()
+	}
+	return str + ")"
+}
+
+// ErrorInfo is an alias of [Error], which is embedded by typed errors.
+type ErrorInfo = Error
+
+// UnsupportedTypeError is returned when the Go type can't be encoded, e.g. a channel or a function.
+type UnsupportedTypeError struct {
+	*ErrorInfo
+}
+
+func (e *UnsupportedTypeError) Unwrap() error        { return e.ErrorInfo }
+func (e *UnsupportedTypeError) Is(target error) bool { return target == ErrUnsupportedType }
+
+// UnsupportedValueError is returned when the value can't be represented in JSON, e.g. NaN or infinite float.
+type UnsupportedValueError struct {
+	*ErrorInfo
+}
+
+func (e *UnsupportedValueError) Unwrap() error        { return e.ErrorInfo }
+func (e *UnsupportedValueError) Is(target error) bool { return target == ErrUnsupportedValue }
+
+// DepthError is returned when the nesting of the value exceeds [MAX_DEPTH], e.g. because of a cyclic pointer.
+type DepthError struct {
+	*ErrorInfo
+}
+
+func (e *DepthError) Unwrap() error        { return e.ErrorInfo }
+func (e *DepthError) Is(target error) bool { return target == ErrDepth }
+
+// MarshalerError wraps an error returned by a custom marshaler, see [Marshaler], [json.Marshaler] and [encoding.TextMarshaler].
+type MarshalerError struct {
+	*ErrorInfo
+	// Err is the error returned by the marshaler.
+	Err error
+}
+
+func (e *MarshalerError) Unwrap() []error      { return []error{e.ErrorInfo, e.Err} }
+func (e *MarshalerError) Is(target error) bool { return target == ErrMarshaler }
+
+// marshalerError wraps an error of a custom marshaler of the value. Errors of the encoder itself, e.g. returned by nested [Encoder.Encode], are returned as is.
+func marshalerError(err error, t reflect.Type) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+	return &MarshalerError{ErrorInfo: &Error{Message: "[Blaze marshaler] " + err.Error(), Type: t}, Err: err}
+}
+
+// withKey prepends an object key to the path of the error.
+func withKey(err error, key string) error {
+	var e *Error
+	if errors.As(err, &e) {
+		switch {
+		case e.Path == "":
+			e.Path = key
+		case e.Path[0] == '[':
+			e.Path = key + e.Path
+		default:
+			e.Path = key + "." + e.Path
+		}
+	}
+	return err
+}
+
+// withIndex prepends an array index to the path of the error.
+func withIndex(err error, i int) error {
+	var e *Error
+	if errors.As(err, &e) {
+		idx := "[" + strconv.Itoa(i) + "]"
+		switch {
+		case e.Path == "" || e.Path[0] == '[':
+			e.Path = idx + e.Path
+		default:
+			e.Path = idx + "." + e.Path
+		}
+	}
+	return err
+}
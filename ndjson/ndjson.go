@@ -0,0 +1,196 @@
+// Package ndjson implements reading and writing of newline-delimited JSON (JSON Lines) with Blaze scopes.
+package ndjson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/deveox/blaze/ctx"
+	"github.com/deveox/blaze/decoder"
+	"github.com/deveox/blaze/encoder"
+	"github.com/deveox/blaze/scopes"
+)
+
+// Error is returned when a record can't be decoded or encoded. It wraps the original error, e.g. [*decoder.Error] with the offset inside the line.
+type Error struct {
+	// Line is the 1-based number of the line with the record.
+	Line int
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("[Blaze ndjson] line %d: %s", e.Line, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Reader reads and decodes newline-delimited JSON records one by one. Empty lines are skipped.
+type Reader struct {
+	config *decoder.Config
+	r      *bufio.Reader
+	line   int
+	buf    []byte
+}
+
+// NewReader creates a new reader which decodes records from r using the given decoder config.
+func NewReader(r io.Reader, c *decoder.Config) *Reader {
+	return &Reader{config: c, r: bufio.NewReader(r)}
+}
+
+// Line returns the number of the last read line.
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// Read decodes the next record into the given value. It returns [io.EOF] when there are no more records.
+func (r *Reader) Read(v any) error {
+	data, err := r.next()
+	if err != nil {
+		return err
+	}
+	return r.wrap(r.config.Unmarshal(data, v))
+}
+
+// ReadCtx works like [Reader.Read], but sets the [*ctx.Ctx] of the decoder.
+func (r *Reader) ReadCtx(v any, ctx *ctx.Ctx) error {
+	data, err := r.next()
+	if err != nil {
+		return err
+	}
+	return r.wrap(r.config.UnmarshalCtx(data, v, ctx))
+}
+
+// ReadScoped decodes the next record into the given value with the given scope. It returns [io.EOF] when there are no more records.
+func (r *Reader) ReadScoped(v any, operation scopes.Decoding) error {
+	data, err := r.next()
+	if err != nil {
+		return err
+	}
+	return r.wrap(r.config.UnmarshalScoped(data, v, operation))
+}
+
+// ReadScopedCtx works like [Reader.ReadScoped], but sets the [*ctx.Ctx] of the decoder.
+func (r *Reader) ReadScopedCtx(v any, operation scopes.Decoding, ctx *ctx.Ctx) error {
+	data, err := r.next()
+	if err != nil {
+		return err
+	}
+	return r.wrap(r.config.UnmarshalScopedCtx(data, v, operation, ctx))
+}
+
+// ReadScopedWithChanges decodes the next record into the given value with the given scope and returns the changes.
+func (r *Reader) ReadScopedWithChanges(v any, operation scopes.Decoding) ([]string, error) {
+	data, err := r.next()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := r.config.UnmarshalScopedWithChanges(data, v, operation)
+	return changes, r.wrap(err)
+}
+
+func (r *Reader) wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Line: r.line, Err: err}
+}
+
+// next returns the next non-empty line without the line terminator.
+func (r *Reader) next() ([]byte, error) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return line, nil
+		}
+	}
+}
+
+func (r *Reader) readLine() ([]byte, error) {
+	r.buf = r.buf[:0]
+	for {
+		line, err := r.r.ReadSlice('\n')
+		switch err {
+		case nil:
+			r.line++
+			if len(r.buf) == 0 {
+				return line, nil
+			}
+			r.buf = append(r.buf, line...)
+			return r.buf, nil
+		case bufio.ErrBufferFull:
+			r.buf = append(r.buf, line...)
+		case io.EOF:
+			r.buf = append(r.buf, line...)
+			if len(r.buf) == 0 {
+				return nil, io.EOF
+			}
+			r.line++
+			return r.buf, nil
+		default:
+			return nil, err
+		}
+	}
+}
+
+// Writer encodes values as newline-delimited JSON records. Each record is written with a single call to the underlying writer.
+type Writer struct {
+	w    io.Writer
+	buf  bytes.Buffer
+	enc  *encoder.StreamEncoder
+	line int
+}
+
+// NewWriter creates a new writer which encodes records to w using the given encoder config.
+func NewWriter(w io.Writer, c *encoder.Config) *Writer {
+	res := &Writer{w: w}
+	res.enc = c.NewStreamEncoder(&res.buf)
+	return res
+}
+
+// Line returns the number of written lines.
+func (w *Writer) Line() int {
+	return w.line
+}
+
+// Write encodes the value and writes it on its own line.
+func (w *Writer) Write(v any) error {
+	w.buf.Reset()
+	return w.flush(w.enc.Encode(v))
+}
+
+// WriteCtx works like [Writer.Write], but sets the [*ctx.Ctx] of the encoder.
+func (w *Writer) WriteCtx(v any, ctx *ctx.Ctx) error {
+	w.buf.Reset()
+	return w.flush(w.enc.EncodeCtx(v, ctx))
+}
+
+// WritePartial works like [encoder.Config.MarshalPartial], but writes the record on its own line.
+func (w *Writer) WritePartial(v any, fields []string, short bool) error {
+	w.buf.Reset()
+	return w.flush(w.enc.EncodePartial(v, fields, short))
+}
+
+// WritePartialCtx works like [Writer.WritePartial], but sets the [*ctx.Ctx] of the encoder.
+func (w *Writer) WritePartialCtx(v any, fields []string, short bool, ctx *ctx.Ctx) error {
+	w.buf.Reset()
+	return w.flush(w.enc.EncodePartialCtx(v, fields, short, ctx))
+}
+
+func (w *Writer) flush(err error) error {
+	if err != nil {
+		return &Error{Line: w.line + 1, Err: err}
+	}
+	if _, err := w.w.Write(w.buf.Bytes()); err != nil {
+		return err
+	}
+	w.line++
+	return nil
+}
@@ -0,0 +1,70 @@
+package ndjson
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/deveox/blaze/decoder"
+	"github.com/deveox/blaze/encoder"
+	"github.com/deveox/blaze/scopes"
+	"github.com/stretchr/testify/require"
+)
+
+type Record struct {
+	ID     int    `blaze:"read"`
+	Name   string `blaze:"client:read.create"`
+	Secret string `blaze:"client:-"`
+}
+
+var clientEncoder = &encoder.Config{Scope: scopes.CONTEXT_CLIENT}
+var clientDecoder = &decoder.Config{Scope: scopes.CONTEXT_CLIENT}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, clientEncoder)
+	require.NoError(t, w.Write(&Record{ID: 1, Name: "first", Secret: "secret"}))
+	require.NoError(t, w.Write(&Record{ID: 2, Name: "second\nline"}))
+	require.NoError(t, w.WritePartial(&Record{ID: 3, Name: "third"}, []string{"name"}, false))
+	require.Equal(t, 3, w.Line())
+	require.Equal(t, `{"id":1,"name":"first"}
+{"id":2,"name":"second\nline"}
+{"name":"third"}
+`, buf.String())
+}
+
+func TestReader(t *testing.T) {
+	data := `{"id":1,"name":"first","secret":"secret"}
+
+{"id":2,"name":"second"}` + "\r\n" + `{"name":"` + strings.Repeat("x", 10000) + `"}`
+	r := NewReader(strings.NewReader(data), clientDecoder)
+	var res []Record
+	for {
+		var v Record
+		err := r.ReadScoped(&v, scopes.DECODE_CREATE)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		res = append(res, v)
+	}
+	require.Equal(t, []Record{{Name: "first"}, {Name: "second"}, {Name: strings.Repeat("x", 10000)}}, res)
+	require.Equal(t, 4, r.Line())
+}
+
+func TestReader_Error(t *testing.T) {
+	data := "{\"name\":\"a\"}\n{\"name\":1}\n"
+	r := NewReader(strings.NewReader(data), &decoder.Config{})
+	var v Record
+	require.NoError(t, r.Read(&v))
+	err := r.Read(&v)
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, 2, e.Line)
+	var de *decoder.Error
+	require.True(t, errors.As(err, &de))
+	require.Equal(t, 8, de.Offset)
+	require.Equal(t, io.EOF, r.Read(&v))
+}
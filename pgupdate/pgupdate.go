@@ -0,0 +1,345 @@
+// Package pgupdate builds parameterized PostgreSQL UPDATE statements from changes reported by the decoder, see [decoder.Config.UnmarshalScopedWithChanges].
+package pgupdate
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/deveox/blaze/encoder"
+	"github.com/deveox/blaze/scopes"
+	"github.com/deveox/blaze/types"
+)
+
+// ErrNoChanges is returned by [Build] when none of the changes is stored in the database, so there is nothing to update.
+var ErrNoChanges = errors.New("[Blaze pgupdate] no changes to update")
+
+// DBEncoder encodes the values of JSON columns. Only fields in the DB scope are encoded.
+var DBEncoder = &encoder.Config{Scope: scopes.CONTEXT_DB}
+
+// Statement is a parameterized SQL statement with numbered placeholders, e.g. `UPDATE users SET "name" = $1`.
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+// Arg adds an argument to the statement and returns its placeholder. It can be used to extend the statement, e.g. with a WHERE clause:
+//
+//	s.SQL += " WHERE id = " + s.Arg(id)
+func (s *Statement) Arg(v any) string {
+	s.Args = append(s.Args, v)
+	return "$" + strconv.Itoa(len(s.Args))
+}
+
+// Build returns an UPDATE statement of the table, which stores the decoded changes of v. The table name is used as is.
+// v must be a struct or a pointer to a struct, changes are dot-separated paths as returned by [decoder.Config.UnmarshalScopedWithChanges].
+//
+// Top-level fields are assigned to their columns, see [types.GetDBName]. Nested structs are stored in JSONB columns,
+// their changed fields are updated with jsonb_set, so the rest of the column is preserved. Changes of maps, slices and arrays update the whole value.
+// Fields outside of the DB scope, e.g. `blaze:"no-db"`, are skipped.
+//
+// Values of JSON columns and composite values are passed as JSON encoded with [DBEncoder], values implementing [driver.Valuer] are passed as is.
+// It returns [ErrNoChanges] if there is nothing to update.
+func Build(table string, v any, changes []string) (*Statement, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, errors.New("[Blaze Build()] expected a struct, got nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("[Blaze Build()] expected a struct, got " + rv.Type().String())
+	}
+	s := types.Cache.Get(rv.Type())
+	targets := make([]*target, 0, len(changes))
+	for _, path := range changes {
+		if t, ok := resolve(s, path); ok {
+			targets = addTarget(targets, t)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, ErrNoChanges
+	}
+
+	st := &Statement{}
+	var b strings.Builder
+	b.WriteString("UPDATE ")
+	b.WriteString(table)
+	b.WriteString(" SET ")
+	done := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		name := t.column.Field.DBName
+		if done[name] {
+			continue
+		}
+		done[name] = true
+		if len(done) > 1 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteString(" = ")
+		var expr string
+		var err error
+		if len(t.fields) == 0 {
+			expr, err = st.columnValue(t.value(rv))
+		} else {
+			expr, err = st.jsonbSet(rv, name, targets)
+		}
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(expr)
+	}
+	st.SQL = b.String()
+	return st, nil
+}
+
+// target is a value updated by the statement: a column, or a field of a struct in a JSON column.
+type target struct {
+	// parents are anonymous struct fields, which fields are stored in their own columns.
+	parents []*types.StructField
+	column  *types.StructField
+	// fields are nested struct fields from the column to the value.
+	fields []*types.StructField
+}
+
+// resolve finds the updated value by the change path. Segments after a map, a slice, an array, a [driver.Valuer] or a value with a custom marshaler
+// are keys and indexes inside of the value, which is updated as a whole. The second return value is false if the value isn't stored in the database.
+func resolve(s *types.Struct, path string) (*target, bool) {
+	t := &target{}
+	for path != "" {
+		var seg string
+		seg, path, _ = strings.Cut(path, ".")
+		f, ok := s.GetField(seg)
+		if !ok || !f.Field.DBScope {
+			return nil, false
+		}
+		switch {
+		case t.column == nil && f.Anonymous && f.Field.Struct != nil:
+			// Anonymous structs with a JSON name are stored in the columns of their fields, like embedded ones.
+			t.parents = append(t.parents, f)
+			s = f.Field.Struct
+			continue
+		case t.column == nil:
+			t.column = f
+		default:
+			t.fields = append(t.fields, f)
+		}
+		if f.Field.Struct == nil || hasCustomMarshaler(f.Field.Type) || implements(f.Field.Type, valuerType) {
+			break
+		}
+		s = f.Field.Struct
+	}
+	return t, t.column != nil
+}
+
+// addTarget adds the target to the list keeping only the most nested ones, e.g. "role.name" replaces "role".
+func addTarget(targets []*target, t *target) []*target {
+	for i, o := range targets {
+		if o.column.Field.DBName != t.column.Field.DBName {
+			continue
+		}
+		if t.within(o) {
+			if len(t.fields) == len(o.fields) {
+				return targets
+			}
+			targets[i] = t
+			return targets
+		}
+		if o.within(t) {
+			return targets
+		}
+	}
+	return append(targets, t)
+}
+
+// within reports whether the target is the same value as o or is nested in it.
+func (t *target) within(o *target) bool {
+	if len(o.fields) > len(t.fields) {
+		return false
+	}
+	for i, f := range o.fields {
+		if t.fields[i].Field.Name != f.Field.Name {
+			return false
+		}
+	}
+	return true
+}
+
+// value returns the updated value of v. It's invalid if one of the parent pointers is nil.
+func (t *target) value(v reflect.Value) reflect.Value {
+	for _, f := range t.parents {
+		v = f.Lookup(v)
+	}
+	v = t.column.Lookup(v)
+	for _, f := range t.fields {
+		v = f.Lookup(v)
+	}
+	return v
+}
+
+// keys returns the keys of the value in the JSON column as a PostgreSQL text array literal, e.g. '{address,city}'.
+func (t *target) keys(n int) string {
+	var b strings.Builder
+	b.WriteString("'{")
+	for i, f := range t.fields[:n] {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeArrayElem(&b, f.Field.Name)
+	}
+	b.WriteString("}'")
+	return b.String()
+}
+
+// columnValue returns the placeholder of the value assigned to a column.
+func (s *Statement) columnValue(v reflect.Value) (string, error) {
+	if !v.IsValid() {
+		return s.Arg(nil), nil
+	}
+	if valuer, ok := asValuer(v); ok {
+		return s.Arg(valuer), nil
+	}
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return s.Arg(nil), nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeFor[time.Time]() {
+			return s.Arg(v.Interface()), nil
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return s.Arg(v.Interface()), nil
+		}
+	case reflect.Map, reflect.Array, reflect.Interface:
+	default:
+		return s.Arg(v.Interface()), nil
+	}
+	return s.jsonValue(v)
+}
+
+// jsonbSet returns the jsonb_set expression, which updates all targets of the column.
+// Missing parent objects are created, because jsonb_set creates only the last key of the path.
+func (s *Statement) jsonbSet(v reflect.Value, column string, targets []*target) (string, error) {
+	expr := "COALESCE(" + column + ", '{}')"
+	ensured := make(map[string]bool)
+	for _, t := range targets {
+		if t.column.Field.DBName != column {
+			continue
+		}
+		for i := 1; i < len(t.fields); i++ {
+			keys := t.keys(i)
+			if ensured[keys] {
+				continue
+			}
+			ensured[keys] = true
+			expr = "jsonb_set(" + expr + ", " + keys + ", COALESCE(NULLIF(" + column + " #> " + keys + ", 'null'), '{}'))"
+		}
+		val, err := s.nestedValue(t.value(v), t.fields[len(t.fields)-1])
+		if err != nil {
+			return "", err
+		}
+		expr = "jsonb_set(" + expr + ", " + t.keys(len(t.fields)) + ", " + val + ")"
+	}
+	return expr, nil
+}
+
+// nestedValue returns the JSONB expression of the value of a field in a JSON column.
+// Plain scalars are passed as is and converted with [types.StructField.PostgreSQLType], other values are encoded with [DBEncoder].
+func (s *Statement) nestedValue(v reflect.Value, f *types.StructField) (string, error) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "'null'", nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "'null'", nil
+	}
+	if !f.Field.StringEncoding && !hasCustomMarshaler(v.Type()) {
+		switch v.Kind() {
+		case reflect.Bool, reflect.String,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return "to_jsonb(" + s.Arg(v.Interface()) + f.PostgreSQLType() + ")", nil
+		}
+	}
+	return s.jsonValue(v)
+}
+
+func (s *Statement) jsonValue(v reflect.Value) (string, error) {
+	// Encode addressable values by pointer, so marshalers with pointer receivers are used.
+	if v.CanAddr() {
+		v = v.Addr()
+	}
+	var buf bytes.Buffer
+	if err := DBEncoder.MarshalTo(&buf, v.Interface()); err != nil {
+		return "", err
+	}
+	return s.Arg(buf.String()) + "::jsonb", nil
+}
+
+var (
+	valuerType        = reflect.TypeFor[driver.Valuer]()
+	stdMarshalerType  = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	marshalerType     = reflect.TypeFor[encoder.Marshaler]()
+)
+
+func asValuer(v reflect.Value) (any, bool) {
+	if v.Type().Implements(valuerType) {
+		return v.Interface(), true
+	}
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(valuerType) {
+		return v.Addr().Interface(), true
+	}
+	return nil, false
+}
+
+// hasCustomMarshaler reports whether the type is encoded by a marshaler, so its JSON representation is defined by the type itself.
+func hasCustomMarshaler(t reflect.Type) bool {
+	return implements(t, marshalerType) || implements(t, stdMarshalerType) || implements(t, textMarshalerType)
+}
+
+// implements reports whether the type or a pointer to it implements the interface.
+func implements(t, i reflect.Type) bool {
+	return t.Implements(i) || reflect.PointerTo(t).Implements(i)
+}
+
+// writeArrayElem writes an element of a PostgreSQL array literal inside of a string constant, quoting it if needed.
+func writeArrayElem(b *strings.Builder, s string) {
+	plain := s != ""
+	for _, c := range s {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		b.WriteString(s)
+		return
+	}
+	b.WriteByte('"')
+	for _, c := range s {
+		switch c {
+		case '"', '\\':
+			b.WriteByte('\\')
+		case '\'':
+			b.WriteByte('\'')
+		}
+		b.WriteRune(c)
+	}
+	b.WriteByte('"')
+}
@@ -0,0 +1,114 @@
+package pgupdate
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	"github.com/deveox/blaze/decoder"
+	"github.com/deveox/blaze/scopes"
+	"github.com/stretchr/testify/require"
+)
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) Value() (driver.Value, error) {
+	return "point", nil
+}
+
+type Address struct {
+	City   string
+	Zip    *int
+	Secret string `blaze:"no-db"`
+}
+
+type Profile struct {
+	Bio     string
+	Address Address
+	Tags    []string
+}
+
+type Timestamps struct {
+	UpdatedBy string
+}
+
+type User struct {
+	Timestamps
+	ID       int
+	Name     string `gorm:"column:full_name"`
+	Profile  Profile
+	Settings map[string]string
+	Location Point
+	Password string `blaze:"no-db"`
+}
+
+var DDecoder = &decoder.Config{Scope: scopes.CONTEXT_ADMIN}
+
+func TestBuild(t *testing.T) {
+	u := &User{Profile: Profile{Bio: "old"}}
+	changes, err := DDecoder.UnmarshalScopedWithChanges([]byte(`{
+		"name": "John",
+		"updatedBy": "admin",
+		"password": "secret",
+		"profile": {"bio": "new", "address": {"city": "Paris", "secret": "x"}, "tags": ["a"]},
+		"settings": {"theme": "dark"},
+		"location": {"x": 1}
+	}`), u, scopes.DECODE_UPDATE)
+	require.NoError(t, err)
+	s, err := Build("users", u, changes)
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE users SET "full_name" = $1, "updated_by" = $2, `+
+		`"profile" = jsonb_set(jsonb_set(jsonb_set(jsonb_set(COALESCE("profile", '{}'), '{bio}', to_jsonb($3::TEXT)), `+
+		`'{address}', COALESCE(NULLIF("profile" #> '{address}', 'null'), '{}')), '{address,city}', to_jsonb($4::TEXT)), '{tags}', $5::jsonb), `+
+		`"settings" = $6::jsonb, "location" = $7`, s.SQL)
+	require.Equal(t, []any{"John", "admin", "new", "Paris", `["a"]`, `{"theme":"dark"}`, Point{X: 1}}, s.Args)
+
+	s.SQL += " WHERE id = " + s.Arg(1)
+	require.Equal(t, "$8", s.SQL[len(s.SQL)-2:])
+}
+
+func TestBuild_Values(t *testing.T) {
+	zip := 75001
+	u := &User{Profile: Profile{Address: Address{Zip: &zip}}}
+	s, err := Build("users", u, []string{"profile", "profile.address", "profile.address.zip", "id"})
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE users SET "profile" = jsonb_set(jsonb_set(COALESCE("profile", '{}'), '{address}', `+
+		`COALESCE(NULLIF("profile" #> '{address}', 'null'), '{}')), '{address,zip}', to_jsonb($1::INTEGER)), "id" = $2`, s.SQL)
+	require.Equal(t, []any{75001, 0}, s.Args)
+
+	u.Profile.Address.Zip = nil
+	s, err = Build("users", u, []string{"profile.address.zip"})
+	require.NoError(t, err)
+	require.Contains(t, s.SQL, `'{address,zip}', 'null')`)
+	require.Empty(t, s.Args)
+
+	s, err = Build("users", u, []string{"profile.address"})
+	require.NoError(t, err)
+	require.Equal(t, `UPDATE users SET "profile" = jsonb_set(COALESCE("profile", '{}'), '{address}', $1::jsonb)`, s.SQL)
+	require.Equal(t, []any{`{}`}, s.Args)
+}
+
+func TestBuild_NoChanges(t *testing.T) {
+	_, err := Build("users", &User{}, []string{"password", "profile.address.secret", "unknown"})
+	require.ErrorIs(t, err, ErrNoChanges)
+	_, err = Build("users", 1, []string{"id"})
+	require.Error(t, err)
+}
+
+func TestWriteArrayElem(t *testing.T) {
+	for in, out := range map[string]string{
+		"city":     "city",
+		"":         `""`,
+		"a b":      `"a b"`,
+		`a"b`:      `"a\"b"`,
+		"it's":     `"it''s"`,
+		"a,b{c}":   `"a,b{c}"`,
+		"snake_09": "snake_09",
+	} {
+		var b strings.Builder
+		writeArrayElem(&b, in)
+		require.Equal(t, out, b.String(), in)
+	}
+}
@@ -1,5 +1,10 @@
 package scopes
 
+import (
+	"sync"
+	"sync/atomic"
+)
+
 type Context int
 
 const (
@@ -7,3 +12,85 @@ const (
 	CONTEXT_CLIENT
 	CONTEXT_DB
 )
+
+// CONTEXT_NONE is used as a parent of a registered context which doesn't inherit from any other context, see [Register].
+const CONTEXT_NONE Context = -1
+
+type contextInfo struct {
+	name   string
+	parent Context
+}
+
+var (
+	registryMu sync.Mutex
+	// registry is replaced as a whole on each registration, so lookups don't need a lock.
+	registry atomic.Pointer[[]contextInfo]
+)
+
+func init() {
+	registry.Store(&[]contextInfo{
+		CONTEXT_ADMIN:  {name: "admin", parent: CONTEXT_NONE},
+		CONTEXT_CLIENT: {name: "client", parent: CONTEXT_NONE},
+		CONTEXT_DB:     {name: "db", parent: CONTEXT_NONE},
+	})
+}
+
+// Register registers a new context with the given name, which can be used in `blaze` tags, e.g. `blaze:"partner:read"`.
+// If a field doesn't define a scope for the context, the scope of the parent context is used.
+// Use [CONTEXT_NONE] as a parent to create a context which uses the scope defined without a context name, e.g. `blaze:"read"`.
+//
+// Contexts should be registered during initialization, before any type with the context in its tags is encoded or decoded.
+// Register panics if the name is already registered or the parent isn't a registered context.
+//
+//	var CONTEXT_PARTNER = scopes.Register("partner", scopes.CONTEXT_CLIENT)
+func Register(name string, parent Context) Context {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	old := *registry.Load()
+	for _, c := range old {
+		if c.name == name {
+			panic("[Blaze Register()] context is already registered: " + name)
+		}
+	}
+	if parent != CONTEXT_NONE && (parent < 0 || int(parent) >= len(old)) {
+		panic("[Blaze Register()] unknown parent context of: " + name)
+	}
+	res := make([]contextInfo, len(old), len(old)+1)
+	copy(res, old)
+	res = append(res, contextInfo{name: name, parent: parent})
+	registry.Store(&res)
+	return Context(len(res) - 1)
+}
+
+// Lookup returns a registered context by its name. The second return value is [false] if the context isn't registered.
+func Lookup(name string) (Context, bool) {
+	for i, c := range *registry.Load() {
+		if c.name == name {
+			return Context(i), true
+		}
+	}
+	return CONTEXT_NONE, false
+}
+
+// Name returns the name of the context as it's used in `blaze` tags.
+func (c Context) Name() string {
+	r := *registry.Load()
+	if c < 0 || int(c) >= len(r) {
+		return "unknown"
+	}
+	return r[c].name
+}
+
+// Parent returns the context, from which the context inherits field scopes. It returns [CONTEXT_NONE] for built-in contexts.
+func (c Context) Parent() Context {
+	r := *registry.Load()
+	if c < 0 || int(c) >= len(r) {
+		return CONTEXT_NONE
+	}
+	return r[c].parent
+}
+
+// IsBuiltin reports whether the context is one of [CONTEXT_ADMIN], [CONTEXT_CLIENT] or [CONTEXT_DB].
+func (c Context) IsBuiltin() bool {
+	return c >= CONTEXT_ADMIN && c <= CONTEXT_DB
+}
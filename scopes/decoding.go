@@ -1,5 +1,7 @@
 package scopes
 
+import "sync/atomic"
+
 type Decoding int
 
 const (
@@ -7,3 +9,64 @@ const (
 	DECODE_CREATE
 	DECODE_UPDATE
 )
+
+// MAX_DECODING is the maximum value of a decoding operation. Each operation takes two bits of a 64-bit field operation set,
+// one allows decoding and one makes the field required.
+const MAX_DECODING Decoding = 31
+
+// decodings is replaced as a whole on each registration, so lookups don't need a lock.
+var decodings atomic.Pointer[[]string]
+
+func init() {
+	decodings.Store(&[]string{
+		DECODE_ANY:    "any",
+		DECODE_CREATE: "create",
+		DECODE_UPDATE: "update",
+	})
+}
+
+// RegisterDecoding registers a new decoding operation with the given name, which can be used in `blaze` tags, e.g. `blaze:"client:read.import"`,
+// and passed to UnmarshalScoped.
+// Fields tagged with `write` or `all` (or without operations at all) can be decoded with any registered operation, other fields only with the listed ones.
+//
+// Operations must be registered during initialization, before any type with the operation in its tags is used, otherwise parsing of the tag panics.
+// RegisterDecoding panics if the name is already registered or there are more than [MAX_DECODING] operations.
+//
+//	var DECODE_IMPORT = scopes.RegisterDecoding("import")
+func RegisterDecoding(name string) Decoding {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	old := *decodings.Load()
+	for _, n := range old {
+		if n == name {
+			panic("[Blaze RegisterDecoding()] decoding operation is already registered: " + name)
+		}
+	}
+	if Decoding(len(old)) > MAX_DECODING {
+		panic("[Blaze RegisterDecoding()] too many decoding operations: " + name)
+	}
+	res := make([]string, len(old), len(old)+1)
+	copy(res, old)
+	res = append(res, name)
+	decodings.Store(&res)
+	return Decoding(len(res) - 1)
+}
+
+// LookupDecoding returns a registered decoding operation by its name. The second return value is [false] if the operation isn't registered.
+func LookupDecoding(name string) (Decoding, bool) {
+	for i, n := range *decodings.Load() {
+		if n == name {
+			return Decoding(i), true
+		}
+	}
+	return DECODE_ANY, false
+}
+
+// Name returns the name of the decoding operation as it's used in `blaze` tags.
+func (d Decoding) Name() string {
+	r := *decodings.Load()
+	if d < 0 || int(d) >= len(r) {
+		return "unknown"
+	}
+	return r[d]
+}
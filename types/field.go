@@ -37,6 +37,8 @@ type StructField struct {
 	Embedded  bool
 	// Reflect path to the field in the struct. Usually there's only one index, but in case of anonymous structs, there can be more.
 	Idx []int
+	// Index of the field in [Struct.Fields].
+	Index int
 }
 
 func (e *StructField) PostgreSQLType() string {
@@ -69,6 +71,24 @@ func (e *StructField) Value(v reflect.Value) reflect.Value {
 	return v
 }
 
+// Lookup returns the [reflect.Value] of the field in the given struct like [StructField.Value], but doesn't allocate nil embedded pointers.
+// It returns an invalid value if the struct or one of the pointers is nil, so it can be used to read values without modifying them.
+func (e *StructField) Lookup(v reflect.Value) reflect.Value {
+	for _, i := range e.Idx {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		if !v.IsValid() {
+			return v
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
 // Field represents a meta info about field in a struct.
 type Field struct {
 	// The native go name of the field.
@@ -84,6 +104,15 @@ type Field struct {
 	ClientScope Operation
 	// Defines if the field should be included in the admin marshaling/unmarshaling
 	AdminScope Operation
+	// Defines if the field should be included in the marshaling/unmarshaling of registered contexts, see [scopes.Register].
+	// Contexts which are not in the map inherit the scope of their parent.
+	Scopes map[scopes.Context]Operation
+	// Defines if the field should be included in the marshaling/unmarshaling of registered contexts without a parent.
+	// It's set by the scope without a context name, e.g. `blaze:"read"`. If the field has only scopes with context names, e.g. `blaze:"client:read"`,
+	// contexts without a parent can't use it, so restricting a field to some contexts never exposes it to others.
+	DefaultScope Operation
+	// Defines the write operations, for which the field is required in all scopes. It's set by `required` tag without a scope, e.g. `blaze:"required:create"`.
+	Required Operation
 
 	// Defines if the field should be kept in the JSON object even if it's empty.
 	KeepEmpty bool
@@ -97,32 +126,73 @@ type Field struct {
 	DBName         string
 	StringEncoding bool
 	StringDecoding bool
+	// Validation rules from the `blaze-validate` tag, checked when the field is decoded.
+	Rules []*Rule
+	// RulesError is an error of parsing the `blaze-validate` tag, it's returned when the field is decoded.
+	RulesError error
 }
 
 // CheckEncoderScope checks if the field can be encoded in the given context.
 func (f *Field) CheckEncoderScope(context scopes.Context) bool {
-	switch context {
-	case scopes.CONTEXT_DB:
+	if context == scopes.CONTEXT_DB {
 		return f.DBScope
-	case scopes.CONTEXT_CLIENT:
-		return f.ClientScope.CanRead()
-	case scopes.CONTEXT_ADMIN:
-		return f.AdminScope.CanRead()
 	}
-	return false
+	return f.operation(context).CanRead()
 }
 
 // CheckDecoderScope checks if the field can be decoded in the given context.
 func (f *Field) CheckDecoderScope(context scopes.Context, scope scopes.Decoding) bool {
-	switch context {
-	case scopes.CONTEXT_DB:
+	if context == scopes.CONTEXT_DB {
 		return f.DBScope
+	}
+	return f.operation(context).CanWrite(scope)
+}
+
+// IsRequired checks if the field must be present and not null when it's decoded in the given context with the given operation.
+// A field can be required only for operations, for which it can be decoded.
+func (f *Field) IsRequired(context scopes.Context, scope scopes.Decoding) bool {
+	if !f.CheckDecoderScope(context, scope) {
+		return false
+	}
+	return (f.Required << OPERATION_REQUIRED_SHIFT).IsRequired(scope) || f.operation(context).IsRequired(scope)
+}
+
+// hasRequired reports if the field is required for any operation in any context.
+func (f *Field) hasRequired() bool {
+	if f.Required != OPERATION_IGNORE || f.ClientScope > OPERATION_ALL || f.AdminScope > OPERATION_ALL || f.DefaultScope > OPERATION_ALL {
+		return true
+	}
+	for _, op := range f.Scopes {
+		if op > OPERATION_ALL {
+			return true
+		}
+	}
+	return false
+}
+
+// operation returns the operations allowed for the field in the given HTTP context.
+func (f *Field) operation(context scopes.Context) Operation {
+	switch context {
 	case scopes.CONTEXT_CLIENT:
-		return f.ClientScope.CanWrite(scope)
+		return f.ClientScope
 	case scopes.CONTEXT_ADMIN:
-		return f.AdminScope.CanWrite(scope)
+		return f.AdminScope
+	case scopes.CONTEXT_DB, scopes.CONTEXT_NONE:
+		return OPERATION_IGNORE
 	}
-	return false
+	if op, ok := f.Scopes[context]; ok {
+		return op
+	}
+	if parent := context.Parent(); parent != scopes.CONTEXT_NONE {
+		if parent == scopes.CONTEXT_DB {
+			if f.DBScope {
+				return OPERATION_ALL
+			}
+			return OPERATION_IGNORE
+		}
+		return f.operation(parent)
+	}
+	return f.DefaultScope
 }
 
 // ParseTag parses the struct tag and populates the field with the data.
@@ -132,11 +202,22 @@ func (f *Field) ParseTag(st reflect.StructTag) {
 	if jsonTag == "-" || tag == "-" {
 		f.ClientScope = OPERATION_IGNORE
 		f.AdminScope = OPERATION_IGNORE
+		f.DefaultScope = OPERATION_IGNORE
+		f.Scopes = nil
+		f.Required = OPERATION_IGNORE
 		f.DBScope = false
 		return
 	}
+	if rules := st.Get(TAG_NAME_VALIDATE); rules != "" {
+		f.Rules, f.RulesError = parseRules(rules, f.Type)
+	}
 	f.DBScope = true
+	f.ClientScope = OPERATION_ALL
+	f.AdminScope = OPERATION_ALL
+	f.DefaultScope = OPERATION_ALL
 	f.Name, _, _ = strings.Cut(jsonTag, ",")
+	// named and bare report if the tag has scopes with and without context names.
+	named, bare := false, false
 loop:
 	for {
 		var v string
@@ -162,18 +243,36 @@ loop:
 		case TAG_NO_HTTP:
 			f.ClientScope = OPERATION_IGNORE
 			f.AdminScope = OPERATION_IGNORE
+			f.DefaultScope = OPERATION_IGNORE
+			f.Scopes = nil
 		default:
-			s, after, _ := strings.Cut(v, ":")
+			s, after, found := strings.Cut(v, ":")
 			switch s {
 			case TAG_SCOPE_CLIENT:
 				f.ClientScope = tagPartToOperation(after)
+				named = true
 			case TAG_SCOPE_ADMIN:
 				f.AdminScope = tagPartToOperation(after)
+				named = true
+			case TAG_SV_REQUIRED:
+				if !found {
+					after = TAG_SV_WRITE
+				}
+				f.Required |= tagPartToOperation(after) & OPERATION_WRITE
 			default:
+				if c, ok := scopes.Lookup(s); found && ok && !c.IsBuiltin() {
+					if f.Scopes == nil {
+						f.Scopes = make(map[scopes.Context]Operation)
+					}
+					f.Scopes[c] = tagPartToOperation(after)
+					named = true
+					break
+				}
 				sc := tagPartToOperation(s)
 				f.ClientScope = sc
 				f.AdminScope = sc
-
+				f.DefaultScope = sc
+				bare = true
 			}
 			continue
 		}
@@ -181,4 +280,7 @@ loop:
 			break
 		}
 	}
+	if named && !bare {
+		f.DefaultScope = OPERATION_IGNORE
+	}
 }
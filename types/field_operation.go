@@ -1,31 +1,52 @@
 package types
 
-import "github.com/deveox/blaze/scopes"
+import (
+	"strings"
 
-type Operation int
+	"github.com/deveox/blaze/scopes"
+)
+
+// Operation is a set of operations allowed for a field. Bit 0 allows reading, bit N allows decoding with [scopes.Decoding] N.
+// Bit N+[OPERATION_REQUIRED_SHIFT] makes the field required for decoding with [scopes.Decoding] N.
+type Operation uint64
+
+// OPERATION_REQUIRED_SHIFT is the offset of the bits which make the field required for decoding operations.
+const OPERATION_REQUIRED_SHIFT = 32
 
 func (s Operation) CanRead() bool {
-	switch s {
-	case OPERATION_READ, OPERATION_READ_CREATE, OPERATION_READ_UPDATE, OPERATION_ALL:
-		return true
-	}
-	return false
+	return s&OPERATION_READ != 0
 }
 
 func (s Operation) CanWrite(scope scopes.Decoding) bool {
-	switch s {
-	case OPERATION_ALL, OPERATION_WRITE:
-		return true
-	case OPERATION_CREATE, OPERATION_READ_CREATE:
-		return scope == scopes.DECODE_CREATE || scope == scopes.DECODE_ANY
-	case OPERATION_UPDATE, OPERATION_READ_UPDATE:
-		return scope == scopes.DECODE_UPDATE || scope == scopes.DECODE_ANY
-	default:
+	if scope == scopes.DECODE_ANY {
+		return s&OPERATION_WRITE != 0
+	}
+	if scope < 0 || scope > scopes.MAX_DECODING {
 		return false
 	}
+	return s&DecodingOperation(scope) != 0
+}
+
+// IsRequired reports if the field must be present and not null when decoding with the given operation.
+func (s Operation) IsRequired(scope scopes.Decoding) bool {
+	if scope <= scopes.DECODE_ANY || scope > scopes.MAX_DECODING {
+		return false
+	}
+	return s&(DecodingOperation(scope)<<OPERATION_REQUIRED_SHIFT) != 0
+}
+
+// DecodingOperation returns an operation which allows decoding with the given [scopes.Decoding].
+func DecodingOperation(scope scopes.Decoding) Operation {
+	if scope == scopes.DECODE_ANY {
+		return OPERATION_WRITE
+	}
+	return 1 << scope
 }
 
 func (s Operation) String() string {
+	if required := s >> OPERATION_REQUIRED_SHIFT; required != 0 {
+		return (s & OPERATION_ALL).String() + ", required for " + strings.TrimSuffix(required.String(), " operation")
+	}
 	switch s {
 	case OPERATION_ALL:
 		return "all operations"
@@ -43,18 +64,27 @@ func (s Operation) String() string {
 		return "read and update operation"
 	case OPERATION_IGNORE:
 		return "no operation"
-	default:
-		return "unknown scope"
 	}
+	names := []string{}
+	if s.CanRead() {
+		names = append(names, "read")
+	}
+	for d := scopes.DECODE_CREATE; d.Name() != "unknown"; d++ {
+		if s.CanWrite(d) {
+			names = append(names, d.Name())
+		}
+	}
+	return strings.Join(names, " and ") + " operation"
 }
 
 const (
-	OPERATION_ALL Operation = iota
-	OPERATION_READ
-	OPERATION_WRITE
-	OPERATION_CREATE
-	OPERATION_READ_CREATE
-	OPERATION_UPDATE
-	OPERATION_READ_UPDATE
-	OPERATION_IGNORE
+	OPERATION_IGNORE      Operation = 0
+	OPERATION_READ        Operation = 1 << 0
+	OPERATION_CREATE      Operation = 1 << scopes.DECODE_CREATE
+	OPERATION_UPDATE      Operation = 1 << scopes.DECODE_UPDATE
+	OPERATION_READ_CREATE           = OPERATION_READ | OPERATION_CREATE
+	OPERATION_READ_UPDATE           = OPERATION_READ | OPERATION_UPDATE
+	// OPERATION_WRITE allows decoding with any operation, including registered ones.
+	OPERATION_WRITE           = OPERATION_ALL &^ OPERATION_READ
+	OPERATION_ALL   Operation = 1<<OPERATION_REQUIRED_SHIFT - 1
 )
@@ -0,0 +1,170 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/deveox/blaze/scopes"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	CONTEXT_PARTNER = scopes.Register("partner", scopes.CONTEXT_CLIENT)
+	CONTEXT_SUPPORT = scopes.Register("support", CONTEXT_PARTNER)
+	CONTEXT_PUBLIC  = scopes.Register("public", scopes.CONTEXT_NONE)
+)
+
+type TestContexts struct {
+	Name      string
+	Inherited string `blaze:"client:read"`
+	Partner   string `blaze:"client:-,partner:read.create"`
+	Support   string `blaze:"partner:-,support:read"`
+	Public    string `blaze:"read,public:-"`
+	AdminOnly string `blaze:"admin:read.write,client:-"`
+	// The scope without a context name applies to contexts without a parent.
+	BareAndNamed string `blaze:"read,client:-"`
+	NoHttp       string `blaze:"no-http"`
+	Ignored      string `blaze:"-"`
+}
+
+func TestField_Contexts(t *testing.T) {
+	require.Equal(t, "partner", CONTEXT_PARTNER.Name())
+	require.Equal(t, scopes.CONTEXT_CLIENT, CONTEXT_PARTNER.Parent())
+	c, ok := scopes.Lookup("support")
+	require.True(t, ok)
+	require.Equal(t, CONTEXT_SUPPORT, c)
+	require.Panics(t, func() { scopes.Register("partner", scopes.CONTEXT_ADMIN) })
+	require.Panics(t, func() { scopes.Register("other", scopes.Context(100)) })
+
+	s := Cache.Get(reflect.TypeOf(TestContexts{}))
+	type check struct {
+		read, create, update bool
+	}
+	tests := map[string]map[scopes.Context]check{
+		"name": {
+			CONTEXT_PARTNER: {true, true, true},
+			CONTEXT_SUPPORT: {true, true, true},
+			CONTEXT_PUBLIC:  {true, true, true},
+		},
+		"inherited": {
+			CONTEXT_PARTNER: {true, false, false},
+			CONTEXT_SUPPORT: {true, false, false},
+			CONTEXT_PUBLIC:  {false, false, false},
+		},
+		"partner": {
+			scopes.CONTEXT_CLIENT: {false, false, false},
+			CONTEXT_PARTNER:       {true, true, false},
+			CONTEXT_SUPPORT:       {true, true, false},
+			CONTEXT_PUBLIC:        {false, false, false},
+		},
+		"support": {
+			CONTEXT_PARTNER: {false, false, false},
+			CONTEXT_SUPPORT: {true, false, false},
+			CONTEXT_PUBLIC:  {false, false, false},
+		},
+		"adminOnly": {
+			scopes.CONTEXT_ADMIN:  {true, true, true},
+			scopes.CONTEXT_CLIENT: {false, false, false},
+			CONTEXT_PUBLIC:        {false, false, false},
+		},
+		"bareAndNamed": {
+			scopes.CONTEXT_CLIENT: {false, false, false},
+			CONTEXT_PUBLIC:        {true, false, false},
+		},
+		"public": {
+			scopes.CONTEXT_ADMIN: {true, false, false},
+			CONTEXT_PARTNER:      {true, false, false},
+			CONTEXT_PUBLIC:       {false, false, false},
+		},
+		"noHttp": {
+			CONTEXT_PARTNER: {false, false, false},
+			CONTEXT_PUBLIC:  {false, false, false},
+		},
+		"ignored": {
+			CONTEXT_SUPPORT: {false, false, false},
+			CONTEXT_PUBLIC:  {false, false, false},
+		},
+	}
+	for name, contexts := range tests {
+		f, ok := s.GetField(name)
+		require.True(t, ok, name)
+		for c, exp := range contexts {
+			require.Equal(t, exp.read, f.Field.CheckEncoderScope(c), "%s %s read", name, c.Name())
+			require.Equal(t, exp.create, f.Field.CheckDecoderScope(c, scopes.DECODE_CREATE), "%s %s create", name, c.Name())
+			require.Equal(t, exp.update, f.Field.CheckDecoderScope(c, scopes.DECODE_UPDATE), "%s %s update", name, c.Name())
+		}
+	}
+}
+
+type TestRequired struct {
+	Name   string `blaze:"required:create"`
+	Email  string `blaze:"client:read.required.update,admin:required"`
+	Role   string `blaze:"required:create,client:read"`
+	Notes  string
+	Ignore string `blaze:"required,-"`
+}
+
+func TestField_Required(t *testing.T) {
+	s := Cache.Get(reflect.TypeOf(TestRequired{}))
+	require.True(t, s.HasRequired)
+	type check struct {
+		create, update bool
+	}
+	tests := map[string]map[scopes.Context]check{
+		"name": {
+			scopes.CONTEXT_CLIENT: {true, false},
+			scopes.CONTEXT_ADMIN:  {true, false},
+			CONTEXT_PARTNER:       {true, false},
+		},
+		"email": {
+			scopes.CONTEXT_CLIENT: {false, true},
+			scopes.CONTEXT_ADMIN:  {true, true},
+			CONTEXT_PARTNER:       {false, true},
+		},
+		"role": {
+			scopes.CONTEXT_CLIENT: {false, false},
+			scopes.CONTEXT_ADMIN:  {true, false},
+		},
+		"notes": {
+			scopes.CONTEXT_ADMIN: {false, false},
+		},
+	}
+	for name, contexts := range tests {
+		f, ok := s.GetField(name)
+		require.True(t, ok, name)
+		for c, exp := range contexts {
+			require.Equal(t, exp.create, f.Field.IsRequired(c, scopes.DECODE_CREATE), "%s %s create", name, c.Name())
+			require.Equal(t, exp.update, f.Field.IsRequired(c, scopes.DECODE_UPDATE), "%s %s update", name, c.Name())
+			require.False(t, f.Field.IsRequired(c, scopes.DECODE_ANY))
+		}
+	}
+	require.False(t, Cache.Get(reflect.TypeOf(TestContexts{})).HasRequired)
+}
+
+type TestLookupEmbedded struct {
+	City string
+}
+
+type TestLookup struct {
+	*TestLookupEmbedded
+	Name string
+}
+
+func TestStructField_Lookup(t *testing.T) {
+	s := Cache.Get(reflect.TypeFor[TestLookup]())
+	city, ok := s.GetField("city")
+	require.True(t, ok)
+	name, ok := s.GetField("name")
+	require.True(t, ok)
+
+	v := TestLookup{Name: "a"}
+	rv := reflect.ValueOf(&v).Elem()
+	require.False(t, city.Lookup(rv).IsValid())
+	require.Nil(t, v.TestLookupEmbedded)
+	require.Equal(t, "a", name.Lookup(rv).String())
+	require.False(t, name.Lookup(reflect.Value{}).IsValid())
+
+	v.TestLookupEmbedded = &TestLookupEmbedded{City: "Paris"}
+	require.Equal(t, "Paris", city.Lookup(rv).String())
+	require.Equal(t, "Paris", city.Lookup(reflect.ValueOf(&v)).String())
+}
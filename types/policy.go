@@ -0,0 +1,59 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/deveox/blaze/ctx"
+)
+
+// PolicyFn decides at runtime if the field can be encoded or decoded.
+// parent is the struct value which holds the field, c is the context of the encoder or decoder.
+type PolicyFn func(c *ctx.Ctx, parent reflect.Value, f *Field) bool
+
+// Policies is a set of runtime field policies keyed by struct type and field name.
+// Policies are checked in addition to the field scopes, a field is skipped if its scope or its policy doesn't allow it.
+//
+// Policies should be set before the config they belong to is used, they are not safe for concurrent modification.
+type Policies struct {
+	m map[*Struct]map[*Field]PolicyFn
+}
+
+// Set sets the policy for the field of the struct type. The field name is the name of the field in JSON, e.g. "email".
+// Fields of embedded structs can be referenced by the embedding struct type, the policy applies only to that type.
+func (p *Policies) Set(t reflect.Type, name string, fn PolicyFn) error {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return errors.New("[Blaze Policies.Set()] expected struct type, got " + t.String())
+	}
+	s := Cache.Get(t)
+	f, ok := s.GetField(name)
+	if !ok {
+		return errors.New("[Blaze Policies.Set()] field not found in " + t.String() + ": " + name)
+	}
+	if p.m == nil {
+		p.m = make(map[*Struct]map[*Field]PolicyFn)
+	}
+	fields := p.m[s]
+	if fields == nil {
+		fields = make(map[*Field]PolicyFn)
+		p.m[s] = fields
+	}
+	fields[f.Field] = fn
+	return nil
+}
+
+// Check reports if the policy of the field allows it. Fields without a policy are always allowed.
+// It's safe to call Check on a nil [*Policies].
+func (p *Policies) Check(c *ctx.Ctx, s *Struct, parent reflect.Value, f *Field) bool {
+	if p == nil || p.m == nil {
+		return true
+	}
+	fn, ok := p.m[s][f]
+	if !ok {
+		return true
+	}
+	return fn(c, parent, f)
+}
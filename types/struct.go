@@ -16,7 +16,9 @@ import (
 type Struct struct {
 	Type reflect.Type
 	// Fields is a list of fields in the struct.
-	Fields      []*StructField
+	Fields []*StructField
+	// HasRequired is true if any field of the struct is required for some operation, see [Field.IsRequired].
+	HasRequired bool
 	byCamelName map[string]*StructField
 }
 
@@ -141,8 +143,12 @@ func (s *Struct) init() {
 		s.initField(f)
 	}
 	s.byCamelName = make(map[string]*StructField, len(s.Fields))
-	for _, f := range s.Fields {
+	for i, f := range s.Fields {
+		f.Index = i
 		s.byCamelName[f.Field.Name] = f
+		if f.Field.hasRequired() {
+			s.HasRequired = true
+		}
 	}
 }
 
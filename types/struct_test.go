@@ -173,8 +173,8 @@ func TestNewStruct(t *testing.T) {
 
 	f, ok = s.GetField("readCreateUpdate")
 	require.True(t, ok, "readCreateUpdate not found")
-	require.Equal(t, OPERATION_ALL, f.Field.ClientScope, "readCreateUpdate client scope is wrong")
-	require.Equal(t, OPERATION_ALL, f.Field.AdminScope, "readCreateUpdate admin scope is wrong")
+	require.Equal(t, OPERATION_READ|OPERATION_CREATE|OPERATION_UPDATE, f.Field.ClientScope, "readCreateUpdate client scope is wrong")
+	require.Equal(t, OPERATION_READ|OPERATION_CREATE|OPERATION_UPDATE, f.Field.AdminScope, "readCreateUpdate admin scope is wrong")
 
 	f, ok = s.GetField("clientAll")
 	require.True(t, ok, "clientAll not found")
@@ -218,7 +218,7 @@ func TestNewStruct(t *testing.T) {
 
 	f, ok = s.GetField("clientReadCreateUpdate")
 	require.True(t, ok, "clientReadCreateUpdate not found")
-	require.Equal(t, OPERATION_ALL, f.Field.ClientScope, "clientReadCreateUpdate client scope is wrong")
+	require.Equal(t, OPERATION_READ|OPERATION_CREATE|OPERATION_UPDATE, f.Field.ClientScope, "clientReadCreateUpdate client scope is wrong")
 	require.Equal(t, OPERATION_ALL, f.Field.AdminScope, "clientReadCreateUpdate admin scope is wrong")
 
 	f, ok = s.GetField("adminAll")
@@ -264,7 +264,7 @@ func TestNewStruct(t *testing.T) {
 	f, ok = s.GetField("adminReadCreateUpdate")
 	require.True(t, ok, "adminReadCreateUpdate not found")
 	require.Equal(t, OPERATION_ALL, f.Field.ClientScope, "adminReadCreateUpdate client scope is wrong")
-	require.Equal(t, OPERATION_ALL, f.Field.AdminScope, "adminReadCreateUpdate admin scope is wrong")
+	require.Equal(t, OPERATION_READ|OPERATION_CREATE|OPERATION_UPDATE, f.Field.AdminScope, "adminReadCreateUpdate admin scope is wrong")
 
 	_, db, ok := s.GetFieldDBPath("nested.name", "->>")
 	require.True(t, ok, "nested.name not found")
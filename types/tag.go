@@ -2,12 +2,16 @@ package types
 
 import (
 	"strings"
+
+	"github.com/deveox/blaze/scopes"
 )
 
 const (
 	// Tag names
 	TAG_NAME_JSON  = "json"
 	TAG_NAME_BLAZE = "blaze"
+	// Validation rules have their own tag, so they don't conflict with other validation libraries using `validate` tag.
+	TAG_NAME_VALIDATE = "blaze-validate"
 
 	// `blaze` tag value
 	TAG_SCOPE_CLIENT     = "client"
@@ -27,55 +31,54 @@ const (
 	TAG_SV_CREATE = "create"
 	TAG_SV_UPDATE = "update"
 	TAG_SV_ALL    = "all"
+	// Makes the field required for the write operations in the same tag part, e.g. `client:required.update`.
+	// Without a scope, e.g. `required:create`, it makes the field required in all scopes, where the operation is allowed.
+	TAG_SV_REQUIRED = "required"
 )
 
+// tagPartToOperation converts operations from a tag to a set of operations.
+// Besides the built-in ones, operations registered with [scopes.RegisterDecoding] are accepted. Only listed operations are allowed,
+// e.g. 'create.update' doesn't allow registered operations, while 'write' and 'all' allow all of them.
+// 'required' makes the field required for all write operations in the part, or for all write operations if there are no other operations.
+//
+// It panics on unknown operations, because an operation registered after the type is cached would be silently ignored.
 func tagPartToOperation(s string) Operation {
-	read := false
-	update := false
-	create := false
-
+	res := OPERATION_IGNORE
+	required := false
 	var v string
 	for {
 		v, s, _ = strings.Cut(s, ".")
 		switch v {
 		case TAG_SV_READ:
-			read = true
+			res |= OPERATION_READ
 		case TAG_SV_WRITE:
-			update = true
-			create = true
+			res |= OPERATION_WRITE
 		case TAG_SV_CREATE:
-			create = true
+			res |= OPERATION_CREATE
 		case TAG_SV_UPDATE:
-			update = true
+			res |= OPERATION_UPDATE
 		case TAG_SV_ALL:
 			return OPERATION_ALL
 		case TAG_SV_IGNORE:
 			return OPERATION_IGNORE
+		case TAG_SV_REQUIRED:
+			required = true
+		default:
+			d, ok := scopes.LookupDecoding(v)
+			if !ok || d == scopes.DECODE_ANY {
+				panic("[Blaze tagPartToOperation()] unknown operation '" + v + "', operations must be registered with scopes.RegisterDecoding before the type is used")
+			}
+			res |= DecodingOperation(d)
 		}
 		if s == "" {
 			break
 		}
 	}
-	if read && update && create {
-		return OPERATION_ALL
-	}
-	if read && update {
-		return OPERATION_READ_UPDATE
-	}
-	if read && create {
-		return OPERATION_READ_CREATE
-	}
-	if read {
-		return OPERATION_READ
-	}
-	if update && create {
-		return OPERATION_WRITE
-	}
-	if update {
-		return OPERATION_UPDATE
-	}
-	if create {
-		return OPERATION_CREATE
+	if required {
+		if res == OPERATION_IGNORE {
+			res = OPERATION_ALL
+		}
+		res |= (res & OPERATION_WRITE) << OPERATION_REQUIRED_SHIFT
 	}
-	return OPERATION_IGNORE
+	return res
 }
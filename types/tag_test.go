@@ -3,13 +3,14 @@ package types
 import (
 	"testing"
 
+	"github.com/deveox/blaze/scopes"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTagToOperation(t *testing.T) {
 	s := "read.update.create"
 	res := tagPartToOperation(s)
-	require.Equal(t, OPERATION_ALL, res)
+	require.Equal(t, OPERATION_READ|OPERATION_CREATE|OPERATION_UPDATE, res)
 
 	s = "read.update"
 	res = tagPartToOperation(s)
@@ -25,7 +26,7 @@ func TestTagToOperation(t *testing.T) {
 
 	s = "update.create"
 	res = tagPartToOperation(s)
-	require.Equal(t, OPERATION_WRITE, res)
+	require.Equal(t, OPERATION_CREATE|OPERATION_UPDATE, res)
 
 	s = "update"
 	res = tagPartToOperation(s)
@@ -48,3 +49,64 @@ func TestTagToOperation(t *testing.T) {
 	require.Equal(t, OPERATION_WRITE, res)
 
 }
+
+var (
+	DECODE_REPLACE    = scopes.RegisterDecoding("replace")
+	DECODE_IMPORT     = scopes.RegisterDecoding("import")
+	DECODE_TRANSITION = scopes.RegisterDecoding("transition")
+)
+
+func TestTagToOperation_Registered(t *testing.T) {
+	res := tagPartToOperation("read.import")
+	require.True(t, res.CanRead())
+	require.True(t, res.CanWrite(DECODE_IMPORT))
+	require.True(t, res.CanWrite(scopes.DECODE_ANY))
+	require.False(t, res.CanWrite(DECODE_REPLACE))
+	require.False(t, res.CanWrite(scopes.DECODE_CREATE))
+	require.Equal(t, "read and import operation", res.String())
+
+	res = tagPartToOperation("update.replace.transition")
+	require.False(t, res.CanRead())
+	require.True(t, res.CanWrite(scopes.DECODE_UPDATE))
+	require.True(t, res.CanWrite(DECODE_REPLACE))
+	require.True(t, res.CanWrite(DECODE_TRANSITION))
+	require.False(t, res.CanWrite(DECODE_IMPORT))
+
+	for _, s := range []string{"write", "all"} {
+		res = tagPartToOperation(s)
+		require.True(t, res.CanWrite(DECODE_IMPORT), s)
+		require.True(t, res.CanWrite(DECODE_TRANSITION), s)
+	}
+	// Only listed operations are allowed.
+	res = tagPartToOperation("read.create.update")
+	require.False(t, res.CanWrite(DECODE_IMPORT))
+	require.False(t, res.CanWrite(DECODE_TRANSITION))
+	require.True(t, res.CanWrite(scopes.DECODE_ANY))
+
+	require.PanicsWithValue(t, "[Blaze tagPartToOperation()] unknown operation 'unregistered', operations must be registered with scopes.RegisterDecoding before the type is used", func() {
+		tagPartToOperation("read.unregistered")
+	})
+	require.Panics(t, func() { tagPartToOperation("read.any") })
+	require.False(t, tagPartToOperation("update").CanWrite(DECODE_REPLACE))
+
+	d, ok := scopes.LookupDecoding("replace")
+	require.True(t, ok)
+	require.Equal(t, DECODE_REPLACE, d)
+	require.Panics(t, func() { scopes.RegisterDecoding("import") })
+}
+
+func TestTagToOperation_Required(t *testing.T) {
+	res := tagPartToOperation("read.required.update")
+	require.True(t, res.CanRead())
+	require.True(t, res.CanWrite(scopes.DECODE_UPDATE))
+	require.False(t, res.CanWrite(scopes.DECODE_CREATE))
+	require.True(t, res.IsRequired(scopes.DECODE_UPDATE))
+	require.False(t, res.IsRequired(scopes.DECODE_CREATE))
+	require.False(t, res.IsRequired(scopes.DECODE_ANY))
+	require.Equal(t, "read and update operation, required for update", res.String())
+
+	res = tagPartToOperation("required")
+	require.True(t, res.CanRead())
+	require.True(t, res.IsRequired(scopes.DECODE_CREATE))
+	require.True(t, res.IsRequired(scopes.DECODE_UPDATE))
+}
@@ -0,0 +1,188 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// `blaze-validate` tag rules, e.g. `blaze-validate:"min=1,max=100,pattern=^[a-z]+$,enum=a|b|c,email"`.
+	// Commas in rule arguments must be escaped with a backslash, e.g. `pattern=^a{1\,3}$`.
+	RULE_MIN     = "min"
+	RULE_MAX     = "max"
+	RULE_PATTERN = "pattern"
+	RULE_ENUM    = "enum"
+	RULE_EMAIL   = "email"
+)
+
+// Rule is a validation rule of a field, parsed from the `blaze-validate` tag.
+type Rule struct {
+	// Name of the rule, e.g. "min".
+	Name string
+	// Argument of the rule as it's written in the tag, e.g. "1".
+	Arg   string
+	check func(v reflect.Value) string
+}
+
+// Check validates the value and returns a message describing the violation, or an empty string if the value is valid.
+// Pointers are dereferenced, nil pointers are always valid.
+func (r *Rule) Check(v reflect.Value) string {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	return r.check(v)
+}
+
+// parseRules parses the `blaze-validate` tag. It returns an error if a rule is unknown or can't be applied to the type.
+func parseRules(tag string, t reflect.Type) ([]*Rule, error) {
+	var rules []*Rule
+	for _, part := range splitEscaped(tag) {
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		r := &Rule{Name: name, Arg: arg}
+		var err string
+		switch name {
+		case RULE_MIN, RULE_MAX:
+			r.check, err = newLimitRule(name == RULE_MIN, arg, t)
+		case RULE_PATTERN:
+			if t.Kind() != reflect.String {
+				err = "pattern rule requires string type"
+				break
+			}
+			re, e := regexp.Compile(arg)
+			if e != nil {
+				err = e.Error()
+				break
+			}
+			r.check = func(v reflect.Value) string {
+				if !re.MatchString(v.String()) {
+					return "must match pattern " + arg
+				}
+				return ""
+			}
+		case RULE_ENUM:
+			r.check, err = newEnumRule(arg, t)
+		case RULE_EMAIL:
+			if t.Kind() != reflect.String {
+				err = "email rule requires string type"
+				break
+			}
+			r.check = func(v reflect.Value) string {
+				s := v.String()
+				if a, e := mail.ParseAddress(s); e != nil || a.Address != s {
+					return "must be a valid email address"
+				}
+				return ""
+			}
+		default:
+			err = "unknown rule"
+		}
+		if err != "" {
+			return nil, errors.New("[Blaze parseRules()] invalid rule '" + part + "' for type " + t.String() + ": " + err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// newLimitRule creates min or max rule. Numbers are compared by value, strings by number of characters, collections by length.
+func newLimitRule(min bool, arg string, t reflect.Type) (func(v reflect.Value) string, string) {
+	limit, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return nil, "expected number"
+	}
+	var get func(v reflect.Value) float64
+	subject := "length"
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		get = func(v reflect.Value) float64 { return float64(v.Int()) }
+		subject = "value"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		get = func(v reflect.Value) float64 { return float64(v.Uint()) }
+		subject = "value"
+	case reflect.Float32, reflect.Float64:
+		get = func(v reflect.Value) float64 { return v.Float() }
+		subject = "value"
+	case reflect.String:
+		get = func(v reflect.Value) float64 { return float64(utf8.RuneCountInString(v.String())) }
+	case reflect.Slice, reflect.Array, reflect.Map:
+		get = func(v reflect.Value) float64 { return float64(v.Len()) }
+	default:
+		return nil, "unsupported type"
+	}
+	if min {
+		msg := subject + " must be at least " + arg
+		return func(v reflect.Value) string {
+			if f := get(v); f < limit || math.IsNaN(f) {
+				return msg
+			}
+			return ""
+		}, ""
+	}
+	msg := subject + " must be at most " + arg
+	return func(v reflect.Value) string {
+		if f := get(v); f > limit || math.IsNaN(f) {
+			return msg
+		}
+		return ""
+	}, ""
+}
+
+// newEnumRule creates enum rule. Values are compared by their string representation.
+func newEnumRule(arg string, t reflect.Type) (func(v reflect.Value) string, string) {
+	values := strings.Split(arg, "|")
+	var str func(v reflect.Value) string
+	switch t.Kind() {
+	case reflect.String:
+		str = reflect.Value.String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		str = func(v reflect.Value) string { return strconv.FormatInt(v.Int(), 10) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		str = func(v reflect.Value) string { return strconv.FormatUint(v.Uint(), 10) }
+	default:
+		return nil, "unsupported type"
+	}
+	msg := "must be one of " + strings.Join(values, ", ")
+	return func(v reflect.Value) string {
+		s := str(v)
+		for _, e := range values {
+			if s == e {
+				return ""
+			}
+		}
+		return msg
+	}, ""
+}
+
+// splitEscaped splits the string by commas, which are not escaped with a backslash.
+func splitEscaped(s string) []string {
+	if !strings.Contains(s, `\,`) {
+		return strings.Split(s, ",")
+	}
+	var res []string
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == ',':
+			sb.WriteByte(',')
+			i++
+		case s[i] == ',':
+			res = append(res, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return append(res, sb.String())
+}
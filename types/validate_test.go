@@ -0,0 +1,63 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func checkRules(rules []*Rule, v any) []string {
+	res := []string{}
+	for _, r := range rules {
+		if msg := r.Check(reflect.ValueOf(v)); msg != "" {
+			res = append(res, msg)
+		}
+	}
+	return res
+}
+
+func mustParseRules(t *testing.T, tag string, typ reflect.Type) []*Rule {
+	rules, err := parseRules(tag, typ)
+	require.NoError(t, err)
+	return rules
+}
+
+func TestRules(t *testing.T) {
+	rules := mustParseRules(t, "min=1,max=10", reflect.TypeFor[int]())
+	require.Empty(t, checkRules(rules, 5))
+	require.Equal(t, []string{"value must be at least 1"}, checkRules(rules, 0))
+	require.Equal(t, []string{"value must be at most 10"}, checkRules(rules, 11))
+
+	rules = mustParseRules(t, "min=0.5", reflect.TypeFor[float64]())
+	require.Equal(t, []string{"value must be at least 0.5"}, checkRules(rules, 0.1))
+
+	rules = mustParseRules(t, `min=2,max=3,pattern=^[a-zа-я]{1\,2}$`, reflect.TypeFor[string]())
+	require.Empty(t, checkRules(rules, "ая"))
+	require.Equal(t, []string{"length must be at least 2"}, checkRules(rules, "a"))
+	require.Equal(t, []string{"must match pattern ^[a-zа-я]{1,2}$"}, checkRules(rules, "abc"))
+
+	rules = mustParseRules(t, "max=2", reflect.TypeFor[[]int]())
+	require.Equal(t, []string{"length must be at most 2"}, checkRules(rules, []int{1, 2, 3}))
+
+	rules = mustParseRules(t, "enum=a|b|c", reflect.TypeFor[string]())
+	require.Empty(t, checkRules(rules, "b"))
+	require.Equal(t, []string{"must be one of a, b, c"}, checkRules(rules, "d"))
+
+	rules = mustParseRules(t, "enum=1|2", reflect.TypeFor[uint8]())
+	require.Empty(t, checkRules(rules, uint8(2)))
+	require.Equal(t, []string{"must be one of 1, 2"}, checkRules(rules, uint8(3)))
+
+	rules = mustParseRules(t, "email", reflect.TypeFor[string]())
+	require.Empty(t, checkRules(rules, "john@example.com"))
+	require.Equal(t, []string{"must be a valid email address"}, checkRules(rules, "John <john@example.com>"))
+	require.Equal(t, []string{"must be a valid email address"}, checkRules(rules, "john"))
+
+	// nil pointers are valid
+	require.Empty(t, checkRules(mustParseRules(t, "min=1", reflect.TypeFor[int]()), (*int)(nil)))
+
+	for _, tag := range []string{"min=a", "unknown", "pattern=(", "email", "min=1"} {
+		_, err := parseRules(tag, reflect.TypeFor[bool]())
+		require.Error(t, err, tag)
+	}
+}